@@ -0,0 +1,219 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+// ManifestName is the name of the manifest file written in the directory.
+const ManifestName = "manifest.json"
+
+// urlPattern matches URLs in Markdown texts.
+var urlPattern = regexp.MustCompile(`https?://[^\s"'<>()\[\]]+`)
+
+// Options specifies the optional parameters to Attachments.
+type Options struct {
+	// Query filters posts to scan. It is the Docbase query syntax.
+	Query string
+
+	// Refresh downloads attachments again even if they are already mirrored.
+	Refresh bool
+}
+
+// Manifest records attachments mirrored in a directory.
+type Manifest struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	Files     []File    `json:"files"`
+}
+
+// File is an attachment mirrored in a directory.
+type File struct {
+	URL string `json:"url"`
+	// Path is a path of the file relative to the directory, with slashes.
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256,omitempty"`
+	// PostIDs are IDs of posts which refer the attachment.
+	PostIDs []docbase.PostID `json:"post_ids"`
+	// Error is a message why the attachment cannot be mirrored.
+	Error string `json:"error,omitempty"`
+}
+
+// Attachments scans bodies and comments of posts in the team for URLs of
+// attachments, and mirrors them to dir with the manifest (see ManifestName).
+//
+// Attachments which are recorded in the existing manifest and whose files
+// exist are not downloaded again, unless opts.Refresh is true. A failure to
+// download an attachment is recorded in the manifest and does not stop the
+// backup; check Error of each file.
+func Attachments(ctx context.Context, client *docbase.Client, dir string, opts Options) (*Manifest, error) {
+	refs := map[string][]docbase.PostID{} // IDs of posts by URLs of attachments
+	list := client.Post.List().PerPage(100)
+	if opts.Query != "" {
+		list.Query(opts.Query)
+	}
+	if err := list.Each(ctx, func(post docbase.Post) error {
+		texts := []string{post.Body}
+		for _, comment := range post.Comments {
+			texts = append(texts, comment.Body)
+		}
+		for _, text := range texts {
+			for _, u := range FindAttachmentURLs(client, text) {
+				if ids := refs[u]; len(ids) == 0 || ids[len(ids)-1] != post.ID {
+					refs[u] = append(ids, post.ID)
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("scan posts: %w", err)
+	}
+
+	previous, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	mirrored := map[string]File{}
+	for _, file := range previous.Files {
+		if file.Error == "" {
+			mirrored[file.URL] = file
+		}
+	}
+
+	urls := make([]string, 0, len(refs))
+	for u := range refs {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+
+	manifest := &Manifest{Files: make([]File, 0, len(urls))}
+	for _, u := range urls {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		ids := refs[u]
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		file := File{URL: u, Path: FilePath(u), PostIDs: ids}
+		if prev, ok := mirrored[u]; ok && !opts.Refresh && exists(filepath.Join(dir, filepath.FromSlash(prev.Path))) {
+			file.Path, file.Size, file.SHA256 = prev.Path, prev.Size, prev.SHA256
+		} else if err := download(ctx, client, dir, &file); err != nil {
+			file.Error = err.Error()
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+	manifest.UpdatedAt = time.Now()
+	if err := writeManifest(dir, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// FindAttachmentURLs finds URLs of attachments in the text, in order of their
+// appearance without duplicates.
+func FindAttachmentURLs(client *docbase.Client, text string) []string {
+	var urls []string
+	found := map[string]bool{}
+	for _, u := range urlPattern.FindAllString(text, -1) {
+		if found[u] || !client.Attachment.IsAttachmentURL(u) {
+			continue
+		}
+		found[u] = true
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// FilePath builds a path to mirror the attachment from its URL:
+// "<host>/<path in the URL>", with slashes.
+func FilePath(attachmentURL string) string {
+	host, p := "", attachmentURL
+	if u, err := url.Parse(attachmentURL); err == nil {
+		host, p = u.Host, u.Path
+	}
+	elems := strings.Split(path.Join(host, path.Clean("/"+p)), "/")
+	for i, elem := range elems {
+		elems[i] = strings.NewReplacer(":", "_", `\`, "_").Replace(elem)
+	}
+	return strings.TrimPrefix(strings.Join(elems, "/"), "/")
+}
+
+// download downloads the attachment to the file, and fills its size and hash.
+func download(ctx context.Context, client *docbase.Client, dir string, file *File) error {
+	dest := filepath.Join(dir, filepath.FromSlash(file.Path))
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	// Write to a temporary file not to leave a broken file.
+	tmp, err := ioutil.TempFile(filepath.Dir(dest), ".download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	_, err = client.Attachment.Download(file.URL).Do(ctx, io.MultiWriter(tmp, hash))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(tmp.Name())
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return err
+	}
+	file.Size = info.Size()
+	file.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// ReadManifest reads the manifest in the directory.
+// If it does not exist, it returns an empty manifest.
+func ReadManifest(dir string) (*Manifest, error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, ManifestName))
+	switch {
+	case os.IsNotExist(err):
+		return &Manifest{}, nil
+	case err != nil:
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest in %s: %w", dir, err)
+	}
+	return &manifest, nil
+}
+
+func writeManifest(dir string, manifest *Manifest) error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, ManifestName), append(content, '\n'), 0644)
+}
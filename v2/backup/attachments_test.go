@@ -0,0 +1,233 @@
+package backup_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/backup"
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+// downloadRecorder records paths of downloaded attachments, and passes
+// requests to the fake server.
+type downloadRecorder struct {
+	server *docbasetest.Server
+
+	mu        sync.Mutex
+	downloads []string
+}
+
+func (h *downloadRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/uploads/") {
+		h.mu.Lock()
+		h.downloads = append(h.downloads, r.URL.Path)
+		h.mu.Unlock()
+	}
+	h.server.ServeHTTP(w, r)
+}
+
+// recorded gets the recorded paths and clears them.
+func (h *downloadRecorder) recorded() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	downloads := h.downloads
+	h.downloads = nil
+	return downloads
+}
+
+func TestAttachments(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	handler := &downloadRecorder{server: server}
+	recorder := httptest.NewServer(handler)
+	defer recorder.Close()
+	client, err := docbase.NewAuthClient(server.Domain, server.Token).WithBaseURL(recorder.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	uploaded, _, err := client.Attachment.Upload().
+		AddPayload("image.png", []byte("png")).
+		AddPayload("manual.pdf", []byte("pdf")).
+		Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The URLs refer the recorder instead of the fake server.
+	urlOf := func(id docbase.AttachmentID, name string) string {
+		return fmt.Sprintf("%s/uploads/%d/%s", recorder.URL, id, name)
+	}
+	image, manual := urlOf(uploaded[0].ID, "image.png"), urlOf(uploaded[1].ID, "manual.pdf")
+	missing := urlOf(999, "missing.png")
+	first := server.AddPost(docbase.Post{
+		Title: "first",
+		Body:  fmt.Sprintf("![image](%s)\n[manual](%s)\n![image again](%s)\nhttps://example.com/uploads/1/other.png", image, manual, image),
+	})
+	second := server.AddPost(docbase.Post{
+		Title:    "second",
+		Body:     "no attachment",
+		Comments: []docbase.Comment{{Body: fmt.Sprintf("see %s and %s", manual, missing)}},
+	})
+
+	dir, err := ioutil.TempDir("", "docbase-backup-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sum := func(content string) string {
+		h := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(h[:])
+	}
+	imageFile := backup.File{URL: image, Path: backup.FilePath(image), Size: 3, SHA256: sum("png"), PostIDs: []docbase.PostID{first.ID}}
+	manualFile := backup.File{URL: manual, Path: backup.FilePath(manual), Size: 3, SHA256: sum("pdf"), PostIDs: []docbase.PostID{first.ID, second.ID}}
+	missingPath := strings.TrimPrefix(missing, recorder.URL)
+
+	check := func(t *testing.T, opts backup.Options, wantDownloads []string) {
+		t.Helper()
+		manifest, err := backup.Attachments(ctx, client, dir, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(manifest.Files) != 3 {
+			t.Fatalf("files = %+v, want 3 of them", manifest.Files)
+		}
+		files := map[string]backup.File{}
+		for _, file := range manifest.Files {
+			files[file.URL] = file
+		}
+		for _, want := range []backup.File{imageFile, manualFile} {
+			got := files[want.URL]
+			if got.Path != want.Path || got.Size != want.Size || got.SHA256 != want.SHA256 || got.Error != "" {
+				t.Errorf("file = %+v, want %+v", got, want)
+			}
+			if fmt.Sprint(got.PostIDs) != fmt.Sprint(want.PostIDs) {
+				t.Errorf("post IDs of %s = %v, want %v", want.URL, got.PostIDs, want.PostIDs)
+			}
+			content, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(want.Path)))
+			if err != nil {
+				t.Error(err)
+			} else if sum(string(content)) != want.SHA256 {
+				t.Errorf("content of %s = %q, want the attachment", want.Path, content)
+			}
+		}
+		if got := files[missing]; got.Error == "" || fmt.Sprint(got.PostIDs) != fmt.Sprint([]docbase.PostID{second.ID}) {
+			t.Errorf("file = %+v, want an error in the post %d", got, second.ID)
+		}
+
+		written, err := backup.ReadManifest(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(written.Files) != len(manifest.Files) || !written.UpdatedAt.Equal(manifest.UpdatedAt) {
+			t.Errorf("written manifest = %+v, want %+v", written, manifest)
+		}
+		for i := range written.Files {
+			if written.Files[i].URL != manifest.Files[i].URL || written.Files[i].Path != manifest.Files[i].Path {
+				t.Errorf("written file = %+v, want %+v", written.Files[i], manifest.Files[i])
+			}
+		}
+		leftovers, err := filepath.Glob(filepath.Join(dir, "*", "uploads", "*", ".download-*"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(leftovers) > 0 {
+			t.Errorf("temporary files %q are left", leftovers)
+		}
+
+		downloads := handler.recorded()
+		if strings.Join(downloads, " ") != strings.Join(wantDownloads, " ") {
+			t.Errorf("downloads = %q, want %q", downloads, wantDownloads)
+		}
+	}
+	imagePath, manualPath := strings.TrimPrefix(image, recorder.URL), strings.TrimPrefix(manual, recorder.URL)
+
+	t.Run("first", func(t *testing.T) {
+		check(t, backup.Options{}, []string{imagePath, manualPath, missingPath})
+	})
+	t.Run("resumed", func(t *testing.T) {
+		// Only the failed one is tried again.
+		check(t, backup.Options{}, []string{missingPath})
+	})
+	t.Run("removed file", func(t *testing.T) {
+		if err := os.Remove(filepath.Join(dir, filepath.FromSlash(imageFile.Path))); err != nil {
+			t.Fatal(err)
+		}
+		check(t, backup.Options{}, []string{imagePath, missingPath})
+	})
+	t.Run("refresh", func(t *testing.T) {
+		check(t, backup.Options{Refresh: true}, []string{imagePath, manualPath, missingPath})
+	})
+}
+
+func TestAttachmentsQuery(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+	uploaded, _, err := client.Attachment.Upload().AddPayload("image.png", []byte("png")).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.AddPost(docbase.Post{Title: "tagged", Body: uploaded[0].Markdown, Tags: []docbase.Tag{{Name: "go"}}})
+	server.AddPost(docbase.Post{Title: "untagged", Body: "none"})
+	dir, err := ioutil.TempDir("", "docbase-backup-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifest, err := backup.Attachments(ctx, client, dir, backup.Options{Query: "tag:rust"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Files) != 0 {
+		t.Errorf("files = %+v, want none", manifest.Files)
+	}
+	manifest, err = backup.Attachments(ctx, client, dir, backup.Options{Query: "tag:go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Files) != 1 || manifest.Files[0].URL != uploaded[0].URL || manifest.Files[0].Error != "" {
+		t.Errorf("files = %+v, want %s", manifest.Files, uploaded[0].URL)
+	}
+}
+
+func TestFindAttachmentURLs(t *testing.T) {
+	client := docbase.NewAuthClient("example", "token")
+	text := `![a](https://image.docbase.io/uploads/a.png)
+[b](https://example.com/uploads/b.png) "https://docbase.io/file_attachments/c.pdf"
+http://image.docbase.io/uploads/d.png https://image.docbase.io/uploads/a.png
+<https://image.docbase.io/other/e.png>`
+	want := []string{"https://image.docbase.io/uploads/a.png", "https://docbase.io/file_attachments/c.pdf"}
+	if got := backup.FindAttachmentURLs(client, text); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("urls = %q, want %q", got, want)
+	}
+}
+
+func TestFilePath(t *testing.T) {
+	for _, test := range []struct {
+		url  string
+		want string
+	}{
+		{url: "https://image.docbase.io/uploads/a/b.png", want: "image.docbase.io/uploads/a/b.png"},
+		{url: "http://127.0.0.1:8080/uploads/1/b.png", want: "127.0.0.1_8080/uploads/1/b.png"},
+		{url: "https://image.docbase.io/uploads/../../etc/passwd", want: "image.docbase.io/etc/passwd"},
+		{url: `https://image.docbase.io/uploads/a\b:c.png`, want: "image.docbase.io/uploads/a_b_c.png"},
+	} {
+		if got := backup.FilePath(test.url); got != test.want {
+			t.Errorf("FilePath(%q) = %q, want %q", test.url, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+/*
+Package backup mirrors assets of a Docbase team to a local directory.
+
+Attachments referenced in bodies and comments of posts are downloaded with
+the manifest which records where each of them came from.
+*/
+package backup
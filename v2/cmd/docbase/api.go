@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+var apiCommand = command{
+	name:    "api",
+	summary: "Send a request to an API endpoint (e.g. api GET posts?q=tag:go)",
+	run:     runAPI,
+}
+
+// paramFlag is a flag of a "key=value" parameter which can be repeated.
+type paramFlag []string
+
+func (p *paramFlag) String() string { return strings.Join(*p, " ") }
+
+func (p *paramFlag) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("parameter %q must be key=value", value)
+	}
+	*p = append(*p, value)
+	return nil
+}
+
+func runAPI(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("api [flags] [<method>] <path>")
+	var params paramFlag
+	flags.Var(&params, "f", "Parameter as key=value (repeatable), sent in the query for GET and DELETE, or in the JSON body for others")
+	input := flags.String("input", "", `File to read the request body in JSON ("-" for the standard input)`)
+	include := flags.Bool("i", false, "Print the status and headers of the response")
+	// Flags can follow the method and the path.
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	method, path := http.MethodGet, ""
+	switch len(positional) {
+	case 1:
+		path = positional[0]
+	case 2:
+		method, path = strings.ToUpper(positional[0]), positional[1]
+	default:
+		return errors.New("path is required: api [<method>] <path>")
+	}
+
+	query := url.Values{}
+	var body interface{}
+	if *input != "" {
+		if len(params) > 0 && method != http.MethodGet && method != http.MethodDelete {
+			return errors.New("-f and -input cannot be specified together")
+		}
+		content, err := readBody("", *input)
+		if err != nil {
+			return err
+		}
+		if !json.Valid([]byte(content)) {
+			return errors.New("request body must be JSON")
+		}
+		body = json.RawMessage(content)
+	}
+	if len(params) > 0 {
+		fields := map[string]interface{}{}
+		for _, param := range params {
+			kv := strings.SplitN(param, "=", 2)
+			if method == http.MethodGet || method == http.MethodDelete {
+				query.Add(kv[0], kv[1])
+			} else {
+				fields[kv[0]] = kv[1]
+			}
+		}
+		if len(fields) > 0 {
+			body = fields
+		}
+	}
+
+	resp, err := client.Call(ctx, method, path, query, body, nil)
+	if *include && resp != nil && resp.Response != nil {
+		printHeader(out.w, resp.Response)
+	}
+	if err != nil {
+		return err
+	}
+	content := resp.Body.Bytes()
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil
+	}
+	if !json.Valid(content) {
+		_, err := out.w.Write(content)
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	return printResult(v)
+}
+
+// printHeader prints the status and headers of the response.
+func printHeader(w io.Writer, resp *http.Response) {
+	fmt.Fprintf(w, "%s %s\n", resp.Proto, resp.Status)
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range resp.Header[name] {
+			fmt.Fprintf(w, "%s: %s\n", name, value)
+		}
+	}
+	fmt.Fprintln(w)
+}
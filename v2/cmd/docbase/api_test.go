@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+func TestAPI(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	server.AddPost(docbase.Post{Title: "first", Body: "body", Tags: []docbase.Tag{{Name: "go"}}})
+	server.AddPost(docbase.Post{Title: "second", Body: "body"})
+	client := server.Client()
+
+	t.Run("get with parameters", func(t *testing.T) {
+		output, err := runCommand(t, client, "api", "posts", "-f", "q=tag:go")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var res struct {
+			Posts []docbase.Post `json:"posts"`
+		}
+		if err := json.Unmarshal([]byte(output), &res); err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Posts) != 1 || res.Posts[0].Title != "first" {
+			t.Errorf("posts = %+v, want the first one", res.Posts)
+		}
+	})
+
+	t.Run("post with parameters", func(t *testing.T) {
+		output, err := runCommand(t, client, "api", "post", "posts", "-f", "title=created", "-f", "body=from api")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var post docbase.Post
+		if err := json.Unmarshal([]byte(output), &post); err != nil {
+			t.Fatal(err)
+		}
+		stored, ok := server.Post(post.ID)
+		if !ok || stored.Title != "created" || stored.Body != "from api" {
+			t.Errorf("post = %+v, want created/from api", stored)
+		}
+	})
+
+	t.Run("leading slash", func(t *testing.T) {
+		output, err := runCommand(t, client, "api", "/teams")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(output, `"domain": "example"`) {
+			t.Errorf("output = %q, want the team", output)
+		}
+	})
+
+	t.Run("include headers", func(t *testing.T) {
+		output, err := runCommand(t, client, "api", "-i", "tags")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(output, "HTTP/1.1 200 OK\n") {
+			t.Errorf("output = %q, want the status first", output)
+		}
+	})
+
+	for _, args := range [][]string{
+		{"api"},
+		{"api", "GET", "posts", "extra"},
+		{"api", "../other"},
+		{"api", "post", "posts", "-f", "invalid"},
+	} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			if _, err := runCommand(t, client, args...); err == nil {
+				t.Error("error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestAPINull(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("null"))
+	}))
+	defer server.Close()
+	client, err := docbase.NewAuthClient("example", "token").WithBaseURL(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := runCommand(t, client, "api", "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "null\n" {
+		t.Errorf("output = %q, want %q", output, "null\n")
+	}
+}
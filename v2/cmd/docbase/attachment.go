@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+var attachmentCommand = command{
+	name: "attachment",
+	subcommands: []command{
+		{name: "upload", summary: "Upload files", run: runAttachmentUpload},
+		{name: "download", summary: "Download an attachment", run: runAttachmentDownload},
+	},
+}
+
+func runAttachmentUpload(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("attachment upload [flags] <file>...")
+	name := flags.String("name", "", `Name of the attachment read from the standard input (file "-")`)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New("files are required")
+	}
+
+	upload := client.Attachment.Upload()
+	for _, path := range flags.Args() {
+		if path == "-" {
+			if *name == "" {
+				return errors.New("-name is required to upload the standard input")
+			}
+			upload.AddReader(*name, os.Stdin)
+			continue
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		upload.AddReader(filepath.Base(path), file)
+	}
+	attachments, _, err := upload.Do(ctx)
+	if len(attachments) > 0 {
+		if perr := printResult(attachments); err == nil {
+			err = perr
+		}
+	}
+	return err
+}
+
+func runAttachmentDownload(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("attachment download [flags] <url>")
+	output := flags.String("o", "", "File to write (default: the standard output)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("URL of the attachment is required")
+	}
+
+	if *output == "" {
+		_, err := client.Attachment.Download(flags.Arg(0)).Do(ctx, out.w)
+		return err
+	}
+	// Write to a temporary file not to leave a broken file.
+	tmp, err := ioutil.TempFile(filepath.Dir(*output), ".download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = client.Attachment.Download(flags.Arg(0)).Do(ctx, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), *output)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+func TestAttachmentDownload(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	uploaded, _, err := client.Attachment.Upload().AddPayload("manual.pdf", []byte("pdf")).Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "docbase-attachment-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	t.Run("standard output", func(t *testing.T) {
+		output, err := runCommand(t, client, "attachment", "download", uploaded[0].URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if output != "pdf" {
+			t.Errorf("output = %q, want %q", output, "pdf")
+		}
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(dir, "manual.pdf")
+		if _, err := runCommand(t, client, "attachment", "download", "-o", path, uploaded[0].URL); err != nil {
+			t.Fatal(err)
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != "pdf" {
+			t.Errorf("content = %q, want %q", content, "pdf")
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		path := filepath.Join(dir, "missing.pdf")
+		if _, err := runCommand(t, client, "attachment", "download", "-o", path, server.URL+"/uploads/999/missing.pdf"); err == nil {
+			t.Error("error = nil, want an error")
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("stat = %v, want the file not to be created", err)
+		}
+		if names, _ := filepath.Glob(filepath.Join(dir, ".download-*")); len(names) > 0 {
+			t.Errorf("temporary files %q are left", names)
+		}
+	})
+
+	t.Run("no url", func(t *testing.T) {
+		if _, err := runCommand(t, client, "attachment", "download", "-o", filepath.Join(dir, "none")); err == nil {
+			t.Error("error = nil, want an error")
+		}
+	})
+}
+
+func TestAttachmentUpload(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	client, recorder, closeRecorder := recordedClient(t, server)
+	defer closeRecorder()
+	dir, err := ioutil.TempDir("", "docbase-attachment-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "image.png")
+	if err := ioutil.WriteFile(path, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := runCommand(t, client, "attachment", "upload", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attachments []docbase.Attachment
+	if err := json.Unmarshal([]byte(output), &attachments); err != nil {
+		t.Fatal(err)
+	}
+	if len(attachments) != 1 || attachments[0].Name != "image.png" {
+		t.Fatalf("attachments = %+v, want image.png", attachments)
+	}
+	if _, content, ok := server.Attachment(attachments[0].ID); !ok || string(content) != "png" {
+		t.Errorf("content = %q, want %q", content, "png")
+	}
+	want := []string{"POST /teams/example/attachments"}
+	if got := recorder.recorded(); !equalStrings(got, want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+
+	for _, args := range [][]string{
+		{"attachment", "upload"},
+		{"attachment", "upload", "-"},
+		{"attachment", "upload", filepath.Join(dir, "missing.png")},
+	} {
+		if _, err := runCommand(t, client, args...); err == nil {
+			t.Errorf("%q: error = nil, want an error", args)
+		}
+		if got := recorder.recorded(); len(got) > 0 {
+			t.Errorf("%q: requests = %q, want none", args, got)
+		}
+	}
+}
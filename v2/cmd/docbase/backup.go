@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kyoh86/go-docbase/v2/backup"
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+var backupCommand = command{
+	name:    "backup",
+	summary: "Mirror attachments referenced in posts with a manifest",
+	run:     runBackup,
+}
+
+func runBackup(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("backup [flags]")
+	dir := flags.String("dir", ".", "Directory to write files")
+	query := flags.String("q", "", "Query to filter posts")
+	refresh := flags.Bool("refresh", false, "Download attachments again even if they are mirrored")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	manifest, err := backup.Attachments(ctx, client, *dir, backup.Options{
+		Query:   *query,
+		Refresh: *refresh,
+	})
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, file := range manifest.Files {
+		if file.Error != "" {
+			failed++
+			fmt.Printf("failed %s: %s\n", file.URL, file.Error)
+			continue
+		}
+		fmt.Println(file.Path)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d attachments failed", failed, len(manifest.Files))
+	}
+	return nil
+}
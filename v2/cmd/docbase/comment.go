@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+var commentCommand = command{
+	name: "comment",
+	subcommands: []command{
+		{name: "list", summary: "List comments of a post", run: runCommentList},
+		{name: "get", summary: "Get a comment of a post", run: runCommentGet},
+		{name: "create", summary: "Create a comment on a post", run: runCommentCreate},
+		{name: "edit", summary: "Edit a comment by recreating it", run: runCommentEdit},
+		{name: "delete", summary: "Delete a comment", run: runCommentDelete},
+	},
+}
+
+func runCommentList(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("comment list [flags] <post id>")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	postID, err := parseID(flags.Args(), "post ID")
+	if err != nil {
+		return err
+	}
+	comments, err := client.Comment.List(docbase.PostID(postID)).All(ctx)
+	if err != nil {
+		return err
+	}
+	if comments == nil {
+		comments = []docbase.Comment{}
+	}
+	return printResult(comments)
+}
+
+// parsePostCommentIDs parses arguments of a post ID and a comment ID.
+func parsePostCommentIDs(args []string) (docbase.PostID, docbase.CommentID, error) {
+	if len(args) != 2 {
+		return 0, 0, errors.New("post ID and comment ID are required")
+	}
+	postID, err := parseID(args[:1], "post ID")
+	if err != nil {
+		return 0, 0, err
+	}
+	commentID, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("invalid comment ID " + strconv.Quote(args[1]))
+	}
+	return docbase.PostID(postID), docbase.CommentID(commentID), nil
+}
+
+func runCommentGet(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("comment get [flags] <post id> <comment id>")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	postID, commentID, err := parsePostCommentIDs(flags.Args())
+	if err != nil {
+		return err
+	}
+	comment, _, err := client.Comment.Get(postID, commentID).Do(ctx)
+	if err != nil {
+		return err
+	}
+	return printResult(comment)
+}
+
+func runCommentCreate(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("comment create [flags] <post id>")
+	body := flags.String("body", "", "Body")
+	bodyFile := flags.String("body-file", "", `File to read the body ("-" for the standard input)`)
+	notice := flags.Bool("notice", true, "Notify members")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	postID, err := parseID(flags.Args(), "post ID")
+	if err != nil {
+		return err
+	}
+	text, err := readBody(*body, *bodyFile)
+	if err != nil {
+		return err
+	}
+	if text == "" {
+		return errors.New("-body (or -body-file) is required")
+	}
+	comment, _, err := client.Comment.Create(docbase.PostID(postID), text).Notice(*notice).Do(ctx)
+	if err != nil {
+		return err
+	}
+	return printResult(comment)
+}
+
+func runCommentEdit(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("comment edit [flags] <post id> <comment id>")
+	body := flags.String("body", "", "Body")
+	bodyFile := flags.String("body-file", "", `File to read the body ("-" for the standard input)`)
+	notice := flags.Bool("notice", false, "Notify members")
+	preserve := flags.Bool("preserve", false, "Preserve the author and the published time (owners only)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	postID, commentID, err := parsePostCommentIDs(flags.Args())
+	if err != nil {
+		return err
+	}
+	text, err := readBody(*body, *bodyFile)
+	if err != nil {
+		return err
+	}
+	if text == "" {
+		return errors.New("-body (or -body-file) is required")
+	}
+	comment, _, err := client.Comment.Edit(postID, commentID).Body(text).Notice(*notice).Preserve(*preserve).Do(ctx)
+	if err != nil {
+		return err
+	}
+	return printResult(comment)
+}
+
+func runCommentDelete(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("comment delete [flags] <comment id>")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	id, err := parseID(flags.Args(), "comment ID")
+	if err != nil {
+		return err
+	}
+	_, err = client.Comment.Delete(docbase.CommentID(id)).Do(ctx)
+	return err
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+func TestParsePostCommentIDs(t *testing.T) {
+	for _, test := range []struct {
+		args        []string
+		wantPost    docbase.PostID
+		wantComment docbase.CommentID
+		wantErr     bool
+	}{
+		{args: []string{"1", "2"}, wantPost: 1, wantComment: 2},
+		{args: nil, wantErr: true},
+		{args: []string{"1"}, wantErr: true},
+		{args: []string{"1", "2", "3"}, wantErr: true},
+		{args: []string{"x", "2"}, wantErr: true},
+		{args: []string{"1", "y"}, wantErr: true},
+	} {
+		post, comment, err := parsePostCommentIDs(test.args)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("parsePostCommentIDs(%q) error = %v, want error: %t", test.args, err, test.wantErr)
+			continue
+		}
+		if post != test.wantPost || comment != test.wantComment {
+			t.Errorf("parsePostCommentIDs(%q) = %d, %d, want %d, %d", test.args, post, comment, test.wantPost, test.wantComment)
+		}
+	}
+}
+
+func TestComment(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	client, recorder, closeRecorder := recordedClient(t, server)
+	defer closeRecorder()
+	post := server.AddPost(docbase.Post{Title: "title", Body: "body"})
+
+	output, err := runCommand(t, client, "comment", "create", "-body", "hello", "-notice=false", fmt.Sprint(post.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var comment docbase.Comment
+	if err := json.Unmarshal([]byte(output), &comment); err != nil {
+		t.Fatal(err)
+	}
+	if comment.Body != "hello" {
+		t.Errorf("comment = %+v, want hello", comment)
+	}
+	want := []string{fmt.Sprintf("POST /teams/example/posts/%d/comments", post.ID)}
+	if got := recorder.recorded(); !equalStrings(got, want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+
+	output, err = runCommand(t, client, "comment", "list", fmt.Sprint(post.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var comments []docbase.Comment
+	if err := json.Unmarshal([]byte(output), &comments); err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 1 || comments[0].ID != comment.ID {
+		t.Errorf("comments = %+v, want the created one", comments)
+	}
+
+	if _, err := runCommand(t, client, "comment", "get", fmt.Sprint(post.ID), fmt.Sprint(comment.ID)); err != nil {
+		t.Errorf("get: %s", err)
+	}
+	recorder.recorded()
+
+	if _, err := runCommand(t, client, "comment", "delete", fmt.Sprint(comment.ID)); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{fmt.Sprintf("DELETE /teams/example/comments/%d", comment.ID)}
+	if got := recorder.recorded(); !equalStrings(got, want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+	if stored, _ := server.Post(post.ID); len(stored.Comments) != 0 {
+		t.Errorf("comments = %+v, want none", stored.Comments)
+	}
+
+	for _, args := range [][]string{
+		{"comment", "create", fmt.Sprint(post.ID)},
+		{"comment", "create", "-body", "", fmt.Sprint(post.ID)},
+		{"comment", "create", "-body", "hello"},
+		{"comment", "create", "-body", "hello", "x"},
+		{"comment", "get", fmt.Sprint(post.ID)},
+		{"comment", "edit", "-body", "hello", fmt.Sprint(post.ID), "y"},
+		{"comment", "list"},
+		{"comment", "delete", "1", "2"},
+	} {
+		if _, err := runCommand(t, client, args...); err == nil {
+			t.Errorf("%q: error = nil, want an error", args)
+		}
+		if got := recorder.recorded(); len(got) > 0 {
+			t.Errorf("%q: requests = %q, want none", args, got)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// hunk is a part of a three-way diff. A stable hunk has the same lines in all
+// versions; others have lines of each version.
+type hunk struct {
+	stable bool
+	base   []string
+	ours   []string
+	theirs []string
+}
+
+// conflict reports whether both versions change the hunk differently.
+func (h hunk) conflict() bool {
+	return !h.stable &&
+		!equalLines(h.ours, h.base) &&
+		!equalLines(h.theirs, h.base) &&
+		!equalLines(h.ours, h.theirs)
+}
+
+// diff3 compares two versions (ours and theirs) derived from the base, and
+// splits them into hunks.
+func diff3(base, ours, theirs []string) []hunk {
+	mo := matchLines(base, ours)
+	mt := matchLines(base, theirs)
+	var hunks []hunk
+	b, o, t := 0, 0, 0
+	for b < len(base) || o < len(ours) || t < len(theirs) {
+		n := 0
+		for b+n < len(base) && mo[b+n] == o+n && mt[b+n] == t+n {
+			n++
+		}
+		if n > 0 {
+			hunks = append(hunks, hunk{stable: true, base: base[b : b+n], ours: ours[o : o+n], theirs: theirs[t : t+n]})
+			b, o, t = b+n, o+n, t+n
+			continue
+		}
+		// Find the next line of the base which both versions keep.
+		next := b
+		for next < len(base) && (mo[next] < 0 || mt[next] < 0) {
+			next++
+		}
+		nextO, nextT := len(ours), len(theirs)
+		if next < len(base) {
+			nextO, nextT = mo[next], mt[next]
+		}
+		hunks = append(hunks, hunk{base: base[b:next], ours: ours[o:nextO], theirs: theirs[t:nextT]})
+		b, o, t = next, nextO, nextT
+	}
+	return hunks
+}
+
+// matchLines finds the longest common subsequence of the lines, and returns
+// indices in b of the lines in a (-1 for lines not in b).
+//
+// It uses the linear space variant of the Myers' algorithm, which takes
+// O((N+M)D) time for D differences, and O(N+M) space.
+func matchLines(a, b []string) []int {
+	m := &matcher{a: a, b: b, matches: make([]int, len(a))}
+	for i := range m.matches {
+		m.matches[i] = -1
+	}
+	size := 2*((len(a)+len(b)+1)/2) + 3
+	m.forward, m.backward = make([]int, size), make([]int, size)
+	m.match(0, len(a), 0, len(b))
+	return m.matches
+}
+
+type matcher struct {
+	a, b    []string
+	matches []int
+	// forward and backward are the furthest reaching points on each diagonal,
+	// reused in each middleSnake.
+	forward, backward []int
+}
+
+// match finds the longest common subsequence of a[a0:a1] and b[b0:b1].
+func (m *matcher) match(a0, a1, b0, b1 int) {
+	for a0 < a1 && b0 < b1 && m.a[a0] == m.b[b0] {
+		m.matches[a0] = b0
+		a0, b0 = a0+1, b0+1
+	}
+	for a0 < a1 && b0 < b1 && m.a[a1-1] == m.b[b1-1] {
+		a1, b1 = a1-1, b1-1
+		m.matches[a1] = b1
+	}
+	if a0 == a1 || b0 == b1 {
+		return
+	}
+	x, y, u, v := m.middleSnake(a0, a1, b0, b1)
+	for i := 0; x+i < u; i++ {
+		m.matches[x+i] = y + i
+	}
+	m.match(a0, x, b0, y)
+	m.match(u, a1, v, b1)
+}
+
+// middleSnake finds the middle snake of the shortest edit script of a[a0:a1]
+// and b[b0:b1]: a diagonal from (x, y) to (u, v) on the optimal path.
+func (m *matcher) middleSnake(a0, a1, b0, b1 int) (x, y, u, v int) {
+	n, l := a1-a0, b1-b0
+	max := (n + l + 1) / 2
+	off := max + 1
+	delta := n - l
+	odd := delta%2 != 0
+	vf, vb := m.forward, m.backward
+	vf[off+1], vb[off+1] = 0, 0
+	for d := 0; d <= max; d++ {
+		// Extend the forward paths on the diagonals k = x - y.
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && vf[off+k-1] < vf[off+k+1]) {
+				x = vf[off+k+1]
+			} else {
+				x = vf[off+k-1] + 1
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < l && m.a[a0+x] == m.b[b0+y] {
+				x, y = x+1, y+1
+			}
+			vf[off+k] = x
+			// The backward path on the same diagonal is on delta - k.
+			if r := delta - k; odd && -(d-1) <= r && r <= d-1 && x+vb[off+r] >= n {
+				return a0 + sx, b0 + sy, a0 + x, b0 + y
+			}
+		}
+		// Extend the backward paths, counting from the ends.
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && vb[off+k-1] < vb[off+k+1]) {
+				x = vb[off+k+1]
+			} else {
+				x = vb[off+k-1] + 1
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < l && m.a[a1-1-x] == m.b[b1-1-y] {
+				x, y = x+1, y+1
+			}
+			vb[off+k] = x
+			if f := delta - k; !odd && -d <= f && f <= d && x+vf[off+f] >= n {
+				return a1 - x, b1 - y, a1 - sx, b1 - sy
+			}
+		}
+	}
+	panic("diff3: no middle snake is found")
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// diffContext is the number of unchanged lines shown around changes.
+const diffContext = 3
+
+// printDiff3 prints hunks changed in either version, in the style of
+// "diff3 -m": conflicts are shown with all versions between markers.
+func printDiff3(w io.Writer, hunks []hunk, oursLabel, baseLabel, theirsLabel string) {
+	for i, h := range hunks {
+		if h.stable {
+			head, tail := 0, 0
+			if i > 0 {
+				head = diffContext
+			}
+			if i < len(hunks)-1 {
+				tail = diffContext
+			}
+			if len(h.base) <= head+tail {
+				printLines(w, h.base)
+				continue
+			}
+			printLines(w, h.base[:head])
+			fmt.Fprintln(w, "...")
+			printLines(w, h.base[len(h.base)-tail:])
+			continue
+		}
+		switch {
+		case h.conflict():
+			fmt.Fprintln(w, "<<<<<<< "+oursLabel)
+			printLines(w, h.ours)
+			fmt.Fprintln(w, "||||||| "+baseLabel)
+			printLines(w, h.base)
+			fmt.Fprintln(w, "=======")
+			printLines(w, h.theirs)
+			fmt.Fprintln(w, ">>>>>>> "+theirsLabel)
+		case equalLines(h.ours, h.base):
+			printLines(w, h.theirs)
+		default:
+			printLines(w, h.ours)
+		}
+	}
+}
+
+func printLines(w io.Writer, lines []string) {
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
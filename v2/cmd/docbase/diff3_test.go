@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// lcsLength calculates the length of the longest common subsequence with a
+// table, to check matchLines.
+func lcsLength(a, b []string) int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				cur[j] = prev[j+1] + 1
+			case prev[j] >= cur[j+1]:
+				cur[j] = prev[j]
+			default:
+				cur[j] = cur[j+1]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[0]
+}
+
+// checkMatches checks that the matches are a longest common subsequence.
+func checkMatches(t *testing.T, a, b []string, matches []int) {
+	t.Helper()
+	if len(matches) != len(a) {
+		t.Fatalf("matches = %v, want %d of them", matches, len(a))
+	}
+	count, last := 0, -1
+	for i, j := range matches {
+		if j < 0 {
+			continue
+		}
+		if j <= last || j >= len(b) || a[i] != b[j] {
+			t.Fatalf("matches = %v for %q and %q: line %d is matched to %d", matches, a, b, i, j)
+		}
+		count, last = count+1, j
+	}
+	if want := lcsLength(a, b); count != want {
+		t.Fatalf("matches = %v for %q and %q: %d lines are matched, want %d", matches, a, b, count, want)
+	}
+}
+
+func TestMatchLines(t *testing.T) {
+	for _, test := range []struct {
+		a, b string
+	}{
+		{a: "", b: ""},
+		{a: "a b c", b: ""},
+		{a: "", b: "a b c"},
+		{a: "a b c", b: "a b c"},
+		{a: "a b c", b: "x y z"},
+		{a: "a b c a b b a", b: "c b a b a c"},
+		{a: "a x b y c", b: "a b c"},
+		{a: "a b c", b: "x a y b z c w"},
+		{a: "a a a a", b: "a a"},
+		{a: "x a b c y", b: "a b c"},
+	} {
+		a, b := strings.Fields(test.a), strings.Fields(test.b)
+		checkMatches(t, a, b, matchLines(a, b))
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rnd.Intn(30))
+		for i := range lines {
+			lines[i] = strconv.Itoa(rnd.Intn(5))
+		}
+		return lines
+	}
+	for i := 0; i < 1000; i++ {
+		a, b := randomLines(), randomLines()
+		checkMatches(t, a, b, matchLines(a, b))
+	}
+}
+
+func TestMatchLinesLarge(t *testing.T) {
+	// A table of the lengths would take gigabytes for them.
+	const n = 50000
+	a, b := make([]string, n), make([]string, n)
+	for i := range a {
+		a[i] = strconv.Itoa(i)
+		b[i] = a[i]
+		if i%1000 == 0 {
+			b[i] = "changed"
+		}
+	}
+	matches := matchLines(a, b)
+	count := 0
+	for _, j := range matches {
+		if j >= 0 {
+			count++
+		}
+	}
+	if want := n - n/1000; count != want {
+		t.Errorf("%d lines are matched, want %d", count, want)
+	}
+}
+
+func TestDiff3(t *testing.T) {
+	for _, test := range []struct {
+		title    string
+		base     string
+		ours     string
+		theirs   string
+		conflict bool
+		want     string
+	}{
+		{
+			title:  "unchanged",
+			base:   "a b c",
+			ours:   "a b c",
+			theirs: "a b c",
+			want:   "...",
+		},
+		{
+			title:  "changed in ours",
+			base:   "a b c",
+			ours:   "a B c",
+			theirs: "a b c",
+			want:   "a B c",
+		},
+		{
+			title:  "changed in both",
+			base:   "a b c d e f g h i",
+			ours:   "A b c d e f g h i",
+			theirs: "a b c d e f g h I",
+			want:   "A b c d ... f g h I",
+		},
+		{
+			title:  "same change",
+			base:   "a b c",
+			ours:   "a x c",
+			theirs: "a x c",
+			want:   "a x c",
+		},
+		{
+			title:  "inserted and deleted",
+			base:   "a b c",
+			ours:   "a b x c",
+			theirs: "b c",
+			want:   "b x c",
+		},
+		{
+			title:    "conflict",
+			base:     "a b c",
+			ours:     "a x c",
+			theirs:   "a y c",
+			conflict: true,
+			want:     "a <<<<<<< ours x ||||||| base b ======= y >>>>>>> theirs c",
+		},
+		{
+			title:    "conflict on appending",
+			base:     "a",
+			ours:     "a x",
+			theirs:   "a y",
+			conflict: true,
+			want:     "a <<<<<<< ours x ||||||| base ======= y >>>>>>> theirs",
+		},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			hunks := diff3(strings.Fields(test.base), strings.Fields(test.ours), strings.Fields(test.theirs))
+			conflict := false
+			for _, h := range hunks {
+				conflict = conflict || h.conflict()
+			}
+			if conflict != test.conflict {
+				t.Errorf("conflict = %t, want %t", conflict, test.conflict)
+			}
+			var buf bytes.Buffer
+			printDiff3(&buf, hunks, "ours", "base", "theirs")
+			// Lines are joined with spaces to compare.
+			got := strings.Replace(strings.TrimSuffix(buf.String(), "\n"), "\n", " ", -1)
+			if got != test.want {
+				t.Errorf("merged = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
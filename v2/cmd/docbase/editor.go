@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/markdown"
+	"gopkg.in/yaml.v3"
+)
+
+// postContent is a part of a post which can be edited in an editor.
+type postContent struct {
+	Title string   `yaml:"title"`
+	Tags  []string `yaml:"tags,flow"`
+	Body  string   `yaml:"-"`
+}
+
+func contentOf(post *docbase.Post) postContent {
+	c := postContent{Title: post.Title, Tags: []string{}, Body: post.Body}
+	for _, tag := range post.Tags {
+		c.Tags = append(c.Tags, tag.Name)
+	}
+	return c
+}
+
+// render writes the content as a Markdown document with front matter.
+func (c postContent) render() string {
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	_ = enc.Encode(c)
+	_ = enc.Close()
+	buf.WriteString("---\n\n")
+	buf.WriteString(c.Body)
+	// ReadDocument removes the newline.
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+// parseContent parses a document rendered by render and edited.
+func parseContent(content []byte) (postContent, error) {
+	doc, err := markdown.ReadDocument(bytes.NewReader(content))
+	if err != nil {
+		return postContent{}, err
+	}
+	c := postContent{Title: strings.TrimSpace(doc.FrontMatter.Title), Tags: []string{}, Body: doc.Body}
+	if c.Title == "" {
+		return postContent{}, errors.New("title is required")
+	}
+	if tags := doc.FrontMatter.Tags; tags != nil && *tags != nil {
+		c.Tags = *tags
+	}
+	return c, nil
+}
+
+// conflicts gets names of fields which both ours and theirs change
+// differently from the base.
+func conflicts(base, ours, theirs postContent) []string {
+	var names []string
+	if ours.Title != base.Title && theirs.Title != base.Title && ours.Title != theirs.Title {
+		names = append(names, "title")
+	}
+	if !equalLines(ours.Tags, base.Tags) && !equalLines(theirs.Tags, base.Tags) && !equalLines(ours.Tags, theirs.Tags) {
+		names = append(names, "tags")
+	}
+	if ours.Body != base.Body && theirs.Body != base.Body && ours.Body != theirs.Body {
+		names = append(names, "body")
+	}
+	return names
+}
+
+func splitLines(text string) []string {
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// editPostInEditor edits the post in the editor, and sends only fields
+// changed in the editor.
+//
+// If the post is changed by someone else while editing and the changes
+// conflict, it shows a three-way diff and keeps the edited file instead of
+// overwriting them, unless force is true. The API cannot edit a post
+// conditionally, so changes made just before sending are not detected.
+func editPostInEditor(ctx context.Context, client *docbase.Client, id docbase.PostID, notice *bool, force bool) error {
+	post, _, err := client.Post.Get(id).Do(ctx)
+	if err != nil {
+		return err
+	}
+	base := contentOf(post)
+
+	file, err := ioutil.TempFile("", fmt.Sprintf("docbase-post-%d-*.md", id))
+	if err != nil {
+		return err
+	}
+	path := file.Name()
+	_, err = file.WriteString(base.render())
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	kept := false
+	defer func() {
+		if !kept {
+			os.Remove(path)
+		}
+	}()
+	// keep keeps the edited file not to lose the edit with the error.
+	keep := func(err error) error {
+		kept = true
+		return fmt.Errorf("%w (the edit is kept in %s)", err, path)
+	}
+
+	if err := runEditor(ctx, path); err != nil {
+		return err
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	ours, err := parseContent(content)
+	if err != nil {
+		return keep(err)
+	}
+	if ours.Title == base.Title && equalLines(ours.Tags, base.Tags) && ours.Body == base.Body {
+		fmt.Fprintln(os.Stderr, "The post is not changed.")
+		return nil
+	}
+
+	latest, _, err := client.Post.Get(id).Do(ctx)
+	if err != nil {
+		return keep(err)
+	}
+	theirs := contentOf(latest)
+	if names := conflicts(base, ours, theirs); len(names) > 0 {
+		hunks := diff3(splitLines(base.render()), splitLines(ours.render()), splitLines(theirs.render()))
+		printDiff3(os.Stderr, hunks, "yours", "original", fmt.Sprintf("post %d (updated at %s)", id, latest.UpdatedAt))
+		if !force {
+			return keep(fmt.Errorf("someone else changed %s of the post %d; use -force to overwrite", strings.Join(names, " and "), id))
+		}
+	}
+
+	edit := client.Post.Edit(id)
+	if ours.Title != base.Title {
+		edit.Title(ours.Title)
+	}
+	if !equalLines(ours.Tags, base.Tags) {
+		edit.Tags(ours.Tags)
+	}
+	if ours.Body != base.Body {
+		edit.Body(ours.Body)
+	}
+	if notice != nil {
+		edit.Notice(*notice)
+	}
+	post, _, err = edit.Do(ctx)
+	if err != nil {
+		return keep(err)
+	}
+	return printResult(post)
+}
+
+// runEditor opens the file in the editor specified by $VISUAL or $EDITOR.
+func runEditor(ctx context.Context, path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		if editor == "" {
+			editor = "notepad"
+		}
+		cmd = exec.CommandContext(ctx, "cmd", "/C", editor+` "`+path+`"`)
+	} else {
+		if editor == "" {
+			editor = "vi"
+		}
+		// The editor may have arguments (e.g. "code --wait").
+		cmd = exec.CommandContext(ctx, "sh", "-c", editor+` "$1"`, "sh", path)
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stderr, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("run editor %q: %w", editor, err)
+	}
+	return nil
+}
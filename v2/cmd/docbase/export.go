@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/markdown"
+)
+
+var exportCommand = command{
+	name:    "export",
+	summary: "Export posts as Markdown files with front matter",
+	run:     runExport,
+}
+
+func runExport(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("export [flags]")
+	dir := flags.String("dir", ".", "Directory to write files")
+	query := flags.String("q", "", "Query to filter posts")
+	comments := flags.Bool("comments", false, "Append comments to each post")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	paths, err := markdown.Export(ctx, client, *dir, markdown.ExportOptions{
+		Query:    *query,
+		Comments: *comments,
+	})
+	for _, path := range paths {
+		fmt.Println(path)
+	}
+	return err
+}
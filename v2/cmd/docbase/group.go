@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+var groupCommand = command{
+	name: "group",
+	subcommands: []command{
+		{name: "list", summary: "List groups", run: runGroupList},
+		{name: "get", summary: "Get a group with its members", run: runGroupGet},
+		{name: "create", summary: "Create a group", run: runGroupCreate},
+		{name: "add-users", summary: "Add users to a group", run: runGroupAddUsers},
+		{name: "remove-users", summary: "Remove users from a group", run: runGroupRemoveUsers},
+	},
+}
+
+func runGroupList(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("group list [flags]")
+	name := flags.String("name", "", "Name to filter groups")
+	page := flags.Int64("page", 1, "Page to get")
+	perPage := flags.Int64("per-page", 20, "Number of groups in a page (max: 100)")
+	all := flags.Bool("all", false, "Get groups in all pages from the page")
+	limit := flags.Int64("limit", 0, "Maximum number of groups to get with -all")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	list := client.Group.List().Page(*page).PerPage(*perPage).Limit(*limit)
+	if *name != "" {
+		list.Name(*name)
+	}
+	if *all {
+		groups, err := list.All(ctx)
+		if err != nil {
+			return err
+		}
+		return printResult(groups)
+	}
+	groups, _, err := list.Do(ctx)
+	if err != nil {
+		return err
+	}
+	return printResult(groups)
+}
+
+func runGroupGet(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("group get [flags] <id or name>")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("group ID or name is required")
+	}
+	ids, err := resolveGroupIDs(ctx, client, flags.Args())
+	if err != nil {
+		return err
+	}
+	group, _, err := client.Group.Get(ids[0]).Do(ctx)
+	if err != nil {
+		return err
+	}
+	return printResult(group)
+}
+
+func runGroupCreate(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("group create [flags] <name>")
+	description := flags.String("description", "", "Description")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("group name is required")
+	}
+	create := client.Group.Create(flags.Arg(0))
+	if *description != "" {
+		create.Description(*description)
+	}
+	group, _, err := create.Do(ctx)
+	if err != nil {
+		return err
+	}
+	return printResult(group)
+}
+
+func runGroupAddUsers(ctx context.Context, client *docbase.Client, args []string) error {
+	return runGroupUsers(ctx, client, args, "group add-users [flags] <group> <user>...", func(group docbase.GroupID, users []docbase.UserID) error {
+		_, err := client.Group.AddUsers(group, users).Do(ctx)
+		return err
+	})
+}
+
+func runGroupRemoveUsers(ctx context.Context, client *docbase.Client, args []string) error {
+	return runGroupUsers(ctx, client, args, "group remove-users [flags] <group> <user>...", func(group docbase.GroupID, users []docbase.UserID) error {
+		_, err := client.Group.RemoveUsers(group, users).Do(ctx)
+		return err
+	})
+}
+
+// runGroupUsers runs an action for users of a group. The group is specified
+// by its ID or name, and the users by their IDs or usernames.
+func runGroupUsers(ctx context.Context, client *docbase.Client, args []string, usage string, action func(docbase.GroupID, []docbase.UserID) error) error {
+	flags := newFlagSet(usage)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 2 {
+		return errors.New("group and users are required")
+	}
+	groups, err := resolveGroupIDs(ctx, client, flags.Args()[:1])
+	if err != nil {
+		return err
+	}
+	users, err := resolveUserIDs(ctx, client, flags.Args()[1:])
+	if err != nil {
+		return err
+	}
+	return action(groups[0], users)
+}
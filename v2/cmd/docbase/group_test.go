@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+func TestGroup(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	client, recorder, closeRecorder := recordedClient(t, server)
+	defer closeRecorder()
+	alice := server.AddUser(docbase.User{Name: "Alice", Username: "alice"})
+	bob := server.AddUser(docbase.User{Name: "Bob", Username: "bob"})
+
+	output, err := runCommand(t, client, "group", "create", "-description", "developers", "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var group docbase.Group
+	if err := json.Unmarshal([]byte(output), &group); err != nil {
+		t.Fatal(err)
+	}
+	if stored, ok := server.Group(group.ID); !ok || stored.Name != "dev" || stored.Description != "developers" {
+		t.Errorf("group = %+v, want dev", stored)
+	}
+
+	// Users are given by their IDs or usernames.
+	if _, err := runCommand(t, client, "group", "add-users", "dev", fmt.Sprint(alice.ID), "bob"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runCommand(t, client, "group", "remove-users", fmt.Sprint(group.ID), "alice"); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := server.Group(group.ID)
+	if len(stored.Users) != 1 || stored.Users[0].ID != bob.ID {
+		t.Errorf("users = %+v, want bob", stored.Users)
+	}
+	recorder.recorded()
+
+	output, err = runCommand(t, client, "group", "list", "-name", "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var groups []docbase.Group
+	if err := json.Unmarshal([]byte(output), &groups); err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || groups[0].ID != group.ID {
+		t.Errorf("groups = %+v, want dev", groups)
+	}
+	want := []string{"GET /teams/example/groups?name=dev&page=1&per_page=20"}
+	if got := recorder.recorded(); !equalStrings(got, want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+
+	if _, err := runCommand(t, client, "group", "get", "dev"); err != nil {
+		t.Errorf("get: %s", err)
+	}
+	if _, err := runCommand(t, client, "group", "add-users", "dev", "carol"); err == nil {
+		t.Error("add an unknown user: error = nil, want an error")
+	}
+	recorder.recorded()
+
+	for _, args := range [][]string{
+		{"group", "create"},
+		{"group", "create", "a", "b"},
+		{"group", "get"},
+		{"group", "add-users", "dev"},
+		{"group", "remove-users"},
+	} {
+		if _, err := runCommand(t, client, args...); err == nil {
+			t.Errorf("%q: error = nil, want an error", args)
+		}
+		if got := recorder.recorded(); len(got) > 0 {
+			t.Errorf("%q: requests = %q, want none", args, got)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/markdown"
+)
+
+var importCommand = command{
+	name:    "import",
+	summary: "Create or update posts from Markdown files with front matter",
+	run:     runImport,
+}
+
+func runImport(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("import [flags]")
+	dir := flags.String("dir", ".", "Directory to read files")
+	dryRun := flags.Bool("dry-run", false, "Show what would be done without changing posts and files")
+	images := flags.Bool("images", false, "Upload local images referenced in bodies and rewrite their links")
+	imageCache := flags.String("image-cache", "", "File to remember uploaded images (default: .docbase-images.json in the directory)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	opts := markdown.ImportOptions{DryRun: *dryRun}
+	if *images && !*dryRun {
+		path := *imageCache
+		if path == "" {
+			path = filepath.Join(*dir, ".docbase-images.json")
+		}
+		cache, err := markdown.LoadUploadCache(path)
+		if err != nil {
+			return err
+		}
+		opts.Images = &markdown.ImageUploader{Client: client, Cache: cache, Root: *dir}
+		defer func() {
+			if err := cache.Save(); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to save the image cache: %s\n", err)
+			}
+		}()
+	}
+
+	results, err := markdown.Import(ctx, client, *dir, opts)
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Printf("%-8s %s: %s\n", result.Action, result.Path, result.Err)
+			continue
+		}
+		fmt.Printf("%-8s %s (%d)\n", result.Action, result.Path, result.PostID)
+	}
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+// docbase is a command-line tool for the Docbase API.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/kyoh86/go-docbase/v2/config"
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+// command is a subcommand of the tool.
+type command struct {
+	name    string
+	summary string
+
+	// run runs the command with arguments following the name.
+	run func(ctx context.Context, client *docbase.Client, args []string) error
+
+	// subcommands are commands under the command. If it has subcommands,
+	// run is ignored.
+	subcommands []command
+}
+
+var commands = []command{
+	postCommand,
+	groupCommand,
+	userCommand,
+	tagCommand,
+	commentCommand,
+	attachmentCommand,
+	exportCommand,
+	importCommand,
+	backupCommand,
+	syncCommand,
+	apiCommand,
+}
+
+func main() {
+	log.SetFlags(0)
+	if err := run(context.Background(), os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		log.Fatalf("docbase: %s", err)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("docbase", flag.ContinueOnError)
+	profileName := flags.String("profile", "", "Profile in the config file (default: $DOCBASE_PROFILE or the default profile)")
+	domain := flags.String("domain", "", "Docbase team domain, overriding the profile")
+	tokenFile := flags.String("token-file", "", `File to read the API token from, overriding the profile ("-" for the standard input, which cannot be used for a body then)`)
+	baseURL := flags.String("base-url", "", "Base URL of the API, overriding the profile")
+	format := flags.String("format", "json", "Format to print results: "+strings.Join(formats, ", "))
+	tmpl := flags.String("template", "", `Go template to print each result with (e.g. "{{.ID}} {{.Title}}")`)
+	fields := flags.String("fields", "", `Comma-separated fields to print, with dots for nested ones (e.g. "id,title,user.username")`)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: docbase [flags] <command> [args]")
+		fmt.Fprintln(flags.Output(), "\nFlags:")
+		flags.PrintDefaults()
+		fmt.Fprintln(flags.Output(), "\nCommands:")
+		printCommands(flags.Output(), "", commands)
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cmd, rest, err := findCommand(commands, flags.Args())
+	if err != nil {
+		flags.Usage()
+		return err
+	}
+	if err := out.configure(*format, *tmpl, *fields); err != nil {
+		return err
+	}
+	client, err := newClient(ctx, *profileName, config.Profile{
+		Domain:    *domain,
+		TokenFile: *tokenFile,
+		BaseURL:   *baseURL,
+	})
+	if err != nil {
+		return err
+	}
+	return cmd.run(ctx, client, rest)
+}
+
+// newClient creates a client with the profile, overridden by the environment
+// variables and then by the flags.
+func newClient(ctx context.Context, name string, flags config.Profile) (*docbase.Client, error) {
+	path, err := config.Path()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := cfg.Profile(name)
+	if err != nil {
+		return nil, err
+	}
+	if flags.Domain != "" {
+		profile.Domain = flags.Domain
+	}
+	switch flags.TokenFile {
+	case "":
+	case "-":
+		// The token is not accepted as a flag value, which would be seen by
+		// others in the process list or in the shell history.
+		token, err := readToken(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		profile.Token, profile.TokenFile, profile.TokenCommand = token, "", ""
+	default:
+		profile.Token, profile.TokenFile, profile.TokenCommand = "", flags.TokenFile, ""
+	}
+	if flags.BaseURL != "" {
+		profile.BaseURL = flags.BaseURL
+	}
+	return profile.NewClient(ctx)
+}
+
+// readToken reads an access token from r.
+func readToken(r io.Reader) (string, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", errors.New("token is empty")
+	}
+	return token, nil
+}
+
+// findCommand finds a command from the arguments, and returns it with the
+// rest of the arguments.
+func findCommand(cmds []command, args []string) (*command, []string, error) {
+	var path []string
+	for {
+		if len(args) == 0 {
+			return nil, nil, fmt.Errorf("command is required: %s", strings.Join(append(path, "<command>"), " "))
+		}
+		var found *command
+		for i := range cmds {
+			if cmds[i].name == args[0] {
+				found = &cmds[i]
+				break
+			}
+		}
+		if found == nil {
+			return nil, nil, fmt.Errorf("unknown command: %s", strings.Join(append(path, args[0]), " "))
+		}
+		path, args = append(path, args[0]), args[1:]
+		if len(found.subcommands) == 0 {
+			return found, args, nil
+		}
+		cmds = found.subcommands
+	}
+}
+
+// printCommands prints names and summaries of the commands.
+func printCommands(w io.Writer, prefix string, cmds []command) {
+	for _, cmd := range cmds {
+		name := strings.TrimSpace(prefix + " " + cmd.name)
+		if len(cmd.subcommands) > 0 {
+			printCommands(w, name, cmd.subcommands)
+			continue
+		}
+		fmt.Fprintf(w, "  %-24s %s\n", name, cmd.summary)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+// requestRecorder records methods, paths and queries of requests, and passes
+// them to the fake server.
+type requestRecorder struct {
+	server *docbasetest.Server
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func (h *requestRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	request := r.Method + " " + r.URL.Path
+	if r.URL.RawQuery != "" {
+		request += "?" + r.URL.RawQuery
+	}
+	h.requests = append(h.requests, request)
+	h.mu.Unlock()
+	h.server.ServeHTTP(w, r)
+}
+
+// recorded gets the recorded requests and clears them.
+func (h *requestRecorder) recorded() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	requests := h.requests
+	h.requests = nil
+	return requests
+}
+
+// recordedClient creates a client of the fake server whose requests are
+// recorded. Call the returned function to close the recorder.
+func recordedClient(t *testing.T, server *docbasetest.Server) (*docbase.Client, *requestRecorder, func()) {
+	t.Helper()
+	handler := &requestRecorder{server: server}
+	recorder := httptest.NewServer(handler)
+	client, err := docbase.NewAuthClient(server.Domain, server.Token).WithBaseURL(recorder.URL + "/")
+	if err != nil {
+		recorder.Close()
+		t.Fatal(err)
+	}
+	return client, handler, recorder.Close
+}
+
+// equalStrings reports whether a and b have the same strings in order.
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// runCommand runs the command found from the arguments with the client, and
+// returns what it prints.
+func runCommand(t *testing.T, client *docbase.Client, args ...string) (string, error) {
+	t.Helper()
+	cmd, rest, err := findCommand(commands, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	saved := out
+	out = &printer{w: &buf, format: "json"}
+	defer func() { out = saved }()
+	err = cmd.run(context.Background(), client, rest)
+	return buf.String(), err
+}
+
+func TestFindCommand(t *testing.T) {
+	for _, test := range []struct {
+		args     []string
+		wantName string
+		wantRest int
+		wantErr  bool
+	}{
+		{args: []string{"api", "posts"}, wantName: "api", wantRest: 1},
+		{args: []string{"post", "get", "1"}, wantName: "get", wantRest: 1},
+		{args: []string{"post"}, wantErr: true},
+		{args: []string{"unknown"}, wantErr: true},
+		{args: nil, wantErr: true},
+	} {
+		cmd, rest, err := findCommand(commands, test.args)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("findCommand(%q) error = nil, want an error", test.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("findCommand(%q) error = %v", test.args, err)
+			continue
+		}
+		if cmd.name != test.wantName || len(rest) != test.wantRest {
+			t.Errorf("findCommand(%q) = %s, %q, want %s with %d args", test.args, cmd.name, rest, test.wantName, test.wantRest)
+		}
+	}
+}
+
+func TestCommandFlagErrors(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	client, recorder, closeRecorder := recordedClient(t, server)
+	defer closeRecorder()
+
+	for _, args := range [][]string{
+		{"sync"},
+		{"sync", "-roster"},
+		{"sync", "-unknown"},
+		{"export", "-unknown"},
+		{"import", "-dry-run=maybe"},
+		{"backup", "-refresh=maybe"},
+	} {
+		if _, err := runCommand(t, client, args...); err == nil {
+			t.Errorf("%q: error = nil, want an error", args)
+		}
+		if got := recorder.recorded(); len(got) > 0 {
+			t.Errorf("%q: requests = %q, want none", args, got)
+		}
+	}
+}
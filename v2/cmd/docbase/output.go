@@ -0,0 +1,410 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"gopkg.in/yaml.v3"
+)
+
+// formats are names of output formats.
+var formats = []string{"json", "jsonl", "yaml", "table", "csv"}
+
+// defaultColumns are fields shown in table and csv formats by default.
+var defaultColumns = map[reflect.Type][]string{
+	reflect.TypeOf(docbase.Post{}):       {"id", "title", "user.username", "draft", "created_at"},
+	reflect.TypeOf(docbase.User{}):       {"id", "username", "name", "role"},
+	reflect.TypeOf(docbase.Group{}):      {"id", "name", "description", "posts_count"},
+	reflect.TypeOf(docbase.Tag{}):        {"name"},
+	reflect.TypeOf(docbase.Comment{}):    {"id", "user.username", "created_at", "body"},
+	reflect.TypeOf(docbase.Attachment{}): {"id", "name", "size", "url"},
+}
+
+// printer prints results of commands.
+type printer struct {
+	w        io.Writer
+	format   string
+	template *template.Template
+	// fields are dotted paths of fields to print (e.g. "user.username").
+	fields []string
+}
+
+// out is the printer for results, configured by the global flags.
+var out = &printer{w: os.Stdout, format: "json"}
+
+// configure sets up the printer with values of the global flags.
+func (p *printer) configure(format, tmpl, fields string) error {
+	valid := false
+	for _, f := range formats {
+		valid = valid || f == format
+	}
+	if !valid {
+		return fmt.Errorf("unknown format %q: use one of %s", format, strings.Join(formats, ", "))
+	}
+	p.format = format
+	if tmpl != "" {
+		t, err := template.New("output").Funcs(template.FuncMap{
+			"json": func(v interface{}) (string, error) {
+				b, err := json.Marshal(v)
+				return string(b), err
+			},
+			"join": strings.Join,
+		}).Parse(tmpl)
+		if err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+		p.template = t
+	}
+	if fields != "" {
+		for _, f := range strings.Split(fields, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				p.fields = append(p.fields, f)
+			}
+		}
+	}
+	return nil
+}
+
+// printResult prints a result of a command, which is a value or a slice of
+// values.
+func printResult(v interface{}) error {
+	return out.print(v)
+}
+
+func (p *printer) print(v interface{}) error {
+	if v == nil {
+		// A JSON null (e.g. from "docbase api") has nothing to be shown in
+		// rows or by the template.
+		switch {
+		case p.template != nil, p.format == "table", p.format == "csv":
+			return nil
+		}
+		_, err := fmt.Fprintln(p.w, "null")
+		return err
+	}
+	items, isList := elements(v)
+	if p.template != nil {
+		return p.printTemplate(items)
+	}
+
+	generic, err := toGeneric(v)
+	if err != nil {
+		return err
+	}
+	var rows []interface{}
+	if isList {
+		rows, _ = generic.([]interface{})
+	} else {
+		rows = []interface{}{generic}
+	}
+	switch p.format {
+	case "table", "csv":
+		columns := p.fields
+		if len(columns) == 0 {
+			columns = columnsFor(reflect.TypeOf(items), rows)
+		}
+		if p.format == "csv" {
+			return writeCSV(p.w, columns, rows)
+		}
+		return writeTable(p.w, columns, rows)
+	}
+
+	if len(p.fields) > 0 {
+		selected := make([]interface{}, len(rows))
+		for i, row := range rows {
+			selected[i] = selectFields(row, p.fields)
+		}
+		rows = selected
+		if isList {
+			generic = rows
+		} else {
+			generic = rows[0]
+		}
+	}
+	switch p.format {
+	case "jsonl":
+		enc := newJSONEncoder(p.w, "")
+		for _, row := range rows {
+			if err := enc.Encode(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "yaml":
+		enc := yaml.NewEncoder(p.w)
+		enc.SetIndent(2)
+		if err := enc.Encode(yamlValue(generic)); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return newJSONEncoder(p.w, "  ").Encode(generic)
+	}
+}
+
+// printTemplate executes the template for each item.
+func (p *printer) printTemplate(items interface{}) error {
+	rv := reflect.ValueOf(items)
+	for i := 0; i < rv.Len(); i++ {
+		var buf bytes.Buffer
+		if err := p.template.Execute(&buf, rv.Index(i).Interface()); err != nil {
+			return err
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		if _, err := p.w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// elements gets the value as a slice, and reports whether it is a slice
+// originally. A pointer is dereferenced.
+func elements(v interface{}) (interface{}, bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		// nil or a nil pointer has no elements.
+		return []interface{}{}, false
+	}
+	if rv.Kind() == reflect.Slice {
+		if rv.IsNil() {
+			// Print an empty list, not null.
+			return reflect.MakeSlice(rv.Type(), 0, 0).Interface(), true
+		}
+		return rv.Interface(), true
+	}
+	s := reflect.MakeSlice(reflect.SliceOf(rv.Type()), 1, 1)
+	s.Index(0).Set(rv)
+	return s.Interface(), false
+}
+
+// columnsFor gets default columns for elements of the slice type.
+// For unknown types, scalar fields in the rows are used.
+func columnsFor(sliceType reflect.Type, rows []interface{}) []string {
+	if columns, ok := defaultColumns[sliceType.Elem()]; ok {
+		return columns
+	}
+	found := map[string]bool{}
+	var columns []string
+	for _, row := range rows {
+		obj, ok := row.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key, value := range obj {
+			switch value.(type) {
+			case map[string]interface{}, []interface{}:
+				continue
+			}
+			if !found[key] {
+				found[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+func newJSONEncoder(w io.Writer, indent string) *json.Encoder {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", indent)
+	enc.SetEscapeHTML(false)
+	return enc
+}
+
+// toGeneric converts the value to maps and slices with keys in JSON.
+func toGeneric(v interface{}) (interface{}, error) {
+	if v != nil {
+		v = fillSlices(reflect.ValueOf(v)).Interface()
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// fillSlices copies the value, replacing nil slices in it with empty ones to
+// be encoded as [], not null.
+func fillSlices(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(fillSlices(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(fillSlices(v.Elem()))
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < c.NumField(); i++ {
+			if f := c.Field(i); f.CanSet() {
+				f.Set(fillSlices(f))
+			}
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.MakeSlice(v.Type(), 0, 0)
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v // []byte is encoded as a string
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(fillSlices(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), fillSlices(iter.Value()))
+		}
+		return c
+	}
+	return v
+}
+
+// yamlValue replaces numbers in the generic value, which would be written as
+// strings in YAML.
+func yamlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case map[string]interface{}:
+		for key, e := range v {
+			v[key] = yamlValue(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = yamlValue(e)
+		}
+	}
+	return value
+}
+
+// selectFields builds an object which has only the fields, keyed by their
+// paths.
+func selectFields(row interface{}, fields []string) map[string]interface{} {
+	selected := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		selected[f] = lookup(row, strings.Split(f, "."))
+	}
+	return selected
+}
+
+// lookup gets the value at the path. A path through an array gets values of
+// all elements.
+func lookup(value interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		return value
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return lookup(v[path[0]], path[1:])
+	case []interface{}:
+		values := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			values = append(values, lookup(e, path))
+		}
+		return values
+	}
+	return nil
+}
+
+// cell formats a value in a cell of a table or a csv.
+// Arrays are joined with commas, and objects are written in JSON.
+func cell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		cells := make([]string, 0, len(v))
+		for _, e := range v {
+			cells = append(cells, cell(e))
+		}
+		return strings.Join(cells, ",")
+	case map[string]interface{}:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+	return fmt.Sprint(value)
+}
+
+func writeCSV(w io.Writer, columns []string, rows []interface{}) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			record[i] = cell(lookup(row, strings.Split(c, ".")))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeTable(w io.Writer, columns []string, rows []interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = strings.ToUpper(strings.Replace(c, ".", "_", -1))
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, c := range columns {
+			// Keep a row in a line.
+			cells[i] = strings.Join(strings.Fields(cell(lookup(row, strings.Split(c, ".")))), " ")
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+func TestPrinterEmptySlices(t *testing.T) {
+	var groups []docbase.Group
+	post := &docbase.Post{ID: 1, Title: "title"}
+	for _, test := range []struct {
+		title  string
+		format string
+		fields string
+		value  interface{}
+		want   string
+	}{
+		{title: "nil list in json", format: "json", value: groups, want: "[]\n"},
+		{title: "nil list in yaml", format: "yaml", value: groups, want: "[]\n"},
+		{title: "nil list in jsonl", format: "jsonl", value: groups, want: ""},
+		{title: "nil list with fields", format: "json", fields: "id", value: groups, want: "[]\n"},
+		{title: "nil list in table", format: "table", value: groups, want: "ID  NAME  DESCRIPTION  POSTS_COUNT\n"},
+		{title: "nil fields", format: "json", fields: "id,tags,groups,comments", value: post, want: `{
+  "comments": [],
+  "groups": [],
+  "id": 1,
+  "tags": []
+}
+`},
+		{title: "nil fields in yaml", format: "yaml", fields: "tags", value: post, want: "tags: []\n"},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := &printer{w: &buf}
+			if err := p.configure(test.format, "", test.fields); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.print(test.value); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != test.want {
+				t.Errorf("output = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPrinterNull(t *testing.T) {
+	for _, test := range []struct {
+		format   string
+		template string
+		want     string
+	}{
+		{format: "json", want: "null\n"},
+		{format: "jsonl", want: "null\n"},
+		{format: "yaml", want: "null\n"},
+		{format: "table", want: ""},
+		{format: "csv", want: ""},
+		{format: "json", template: "{{.id}}", want: ""},
+	} {
+		t.Run(test.format+test.template, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := &printer{w: &buf}
+			if err := p.configure(test.format, test.template, ""); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.print(nil); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != test.want {
+				t.Errorf("output = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestElementsNilPointer(t *testing.T) {
+	var post *docbase.Post
+	items, isList := elements(post)
+	if isList || reflect.ValueOf(items).Len() != 0 {
+		t.Errorf("elements = %v, %t, want no elements", items, isList)
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"time"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+var postCommand = command{
+	name: "post",
+	subcommands: []command{
+		{name: "list", summary: "List posts", run: runPostList},
+		{name: "get", summary: "Get a post", run: runPostGet},
+		{name: "create", summary: "Create a post", run: runPostCreate},
+		{name: "edit", summary: "Edit a post (in $EDITOR without changes in flags)", run: runPostEdit},
+		{name: "archive", summary: "Archive a post", run: runPostArchive},
+		{name: "unarchive", summary: "Unarchive a post", run: runPostUnarchive},
+		{name: "delete", summary: "Delete a post", run: runPostDelete},
+	},
+}
+
+func runPostList(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("post list [flags]")
+	query := flags.String("q", "", "Query to filter posts")
+	page := flags.Int64("page", 1, "Page to get")
+	perPage := flags.Int64("per-page", 20, "Number of posts in a page (max: 100)")
+	all := flags.Bool("all", false, "Get posts in all pages from the page")
+	limit := flags.Int64("limit", 0, "Maximum number of posts to get with -all")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	list := client.Post.List().Page(*page).PerPage(*perPage).Limit(*limit)
+	if *query != "" {
+		list.Query(*query)
+	}
+	if *all {
+		posts, err := list.All(ctx)
+		if err != nil {
+			return err
+		}
+		return printResult(posts)
+	}
+	posts, _, err := list.Do(ctx)
+	if err != nil {
+		return err
+	}
+	return printResult(posts)
+}
+
+func runPostGet(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("post get [flags] <id>")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	id, err := parseID(flags.Args(), "post ID")
+	if err != nil {
+		return err
+	}
+	post, _, err := client.Post.Get(docbase.PostID(id)).Do(ctx)
+	if err != nil {
+		return err
+	}
+	return printResult(post)
+}
+
+// postFlags are flags to create or edit a post.
+type postFlags struct {
+	title    *string
+	body     *string
+	bodyFile *string
+	draft    *bool
+	notice   *bool
+	scope    *string
+	tags     listFlag
+	groups   listFlag
+}
+
+func newPostFlags(usage string) (*postFlags, *flag.FlagSet) {
+	flags := newFlagSet(usage)
+	f := &postFlags{
+		title:    flags.String("title", "", "Title"),
+		body:     flags.String("body", "", "Body"),
+		bodyFile: flags.String("body-file", "", `File to read the body ("-" for the standard input)`),
+		draft:    flags.Bool("draft", false, "Save as a draft"),
+		notice:   flags.Bool("notice", true, "Notify members"),
+		scope:    flags.String("scope", "", "Scope: everyone, group or private"),
+	}
+	flags.Var(&f.tags, "tag", "Tag (repeatable or comma-separated)")
+	flags.Var(&f.groups, "group", "Group ID or name (repeatable or comma-separated)")
+	return f, flags
+}
+
+func runPostCreate(ctx context.Context, client *docbase.Client, args []string) error {
+	f, flags := newPostFlags("post create [flags]")
+	authorID := flags.Int64("author-id", 0, "ID of the author (owners only)")
+	publishedAt := flags.String("published-at", "", "Published time in RFC 3339 (owners only)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	body, err := readBody(*f.body, *f.bodyFile)
+	if err != nil {
+		return err
+	}
+	if *f.title == "" || body == "" {
+		return errors.New("-title and -body (or -body-file) are required")
+	}
+
+	create := client.Post.Create(*f.title, body).Draft(*f.draft).Notice(*f.notice)
+	if len(f.tags) > 0 {
+		create.Tags(f.tags)
+	}
+	if len(f.groups) > 0 {
+		ids, err := resolveGroupIDs(ctx, client, f.groups)
+		if err != nil {
+			return err
+		}
+		create.Groups(ids)
+	}
+	if *f.scope != "" {
+		create.Scope(docbase.Scope(*f.scope))
+	} else if len(f.groups) > 0 {
+		create.Scope(docbase.ScopeGroup)
+	}
+	if *authorID != 0 {
+		create.AuthorID(docbase.UserID(*authorID))
+	}
+	if *publishedAt != "" {
+		at, err := time.Parse(time.RFC3339, *publishedAt)
+		if err != nil {
+			return err
+		}
+		create.PublishedAt(at)
+	}
+	post, _, err := create.Do(ctx)
+	if err != nil {
+		return err
+	}
+	return printResult(post)
+}
+
+func runPostEdit(ctx context.Context, client *docbase.Client, args []string) error {
+	f, flags := newPostFlags("post edit [flags] <id>")
+	force := flags.Bool("force", false, "Overwrite changes made by someone else while editing in the editor")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	id, err := parseID(flags.Args(), "post ID")
+	if err != nil {
+		return err
+	}
+
+	// Edit in the editor if no change is specified.
+	editing := false
+	for _, name := range []string{"title", "body", "body-file", "draft", "tag", "group", "scope"} {
+		editing = editing || isSet(flags, name)
+	}
+	if !editing {
+		var notice *bool
+		if isSet(flags, "notice") {
+			notice = f.notice
+		}
+		return editPostInEditor(ctx, client, docbase.PostID(id), notice, *force)
+	}
+
+	// Change only what is specified.
+	edit := client.Post.Edit(docbase.PostID(id))
+	if isSet(flags, "title") {
+		edit.Title(*f.title)
+	}
+	if isSet(flags, "body") || isSet(flags, "body-file") {
+		body, err := readBody(*f.body, *f.bodyFile)
+		if err != nil {
+			return err
+		}
+		edit.Body(body)
+	}
+	if isSet(flags, "draft") {
+		edit.Draft(*f.draft)
+	}
+	if isSet(flags, "notice") {
+		edit.Notice(*f.notice)
+	}
+	if isSet(flags, "tag") {
+		edit.Tags(append([]string{}, f.tags...))
+	}
+	if isSet(flags, "group") {
+		ids, err := resolveGroupIDs(ctx, client, f.groups)
+		if err != nil {
+			return err
+		}
+		edit.Groups(ids)
+	}
+	if *f.scope != "" {
+		edit.Scope(docbase.Scope(*f.scope))
+	}
+	post, _, err := edit.Do(ctx)
+	if err != nil {
+		return err
+	}
+	return printResult(post)
+}
+
+func runPostArchive(ctx context.Context, client *docbase.Client, args []string) error {
+	return runPostAction(ctx, args, "post archive <id>", func(id docbase.PostID) error {
+		_, err := client.Post.Archive(id).Do(ctx)
+		return err
+	})
+}
+
+func runPostUnarchive(ctx context.Context, client *docbase.Client, args []string) error {
+	return runPostAction(ctx, args, "post unarchive <id>", func(id docbase.PostID) error {
+		_, err := client.Post.Unarchive(id).Do(ctx)
+		return err
+	})
+}
+
+func runPostDelete(ctx context.Context, client *docbase.Client, args []string) error {
+	return runPostAction(ctx, args, "post delete <id>", func(id docbase.PostID) error {
+		_, err := client.Post.Delete(id).Do(ctx)
+		return err
+	})
+}
+
+// runPostAction runs an action for a post specified by the argument.
+func runPostAction(ctx context.Context, args []string, usage string, action func(docbase.PostID) error) error {
+	flags := newFlagSet(usage)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	id, err := parseID(flags.Args(), "post ID")
+	if err != nil {
+		return err
+	}
+	return action(docbase.PostID(id))
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+func TestPost(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	client, recorder, closeRecorder := recordedClient(t, server)
+	defer closeRecorder()
+	dev := server.AddGroup(docbase.Group{Name: "dev"})
+	server.AddPost(docbase.Post{Title: "other", Body: "body"})
+
+	output, err := runCommand(t, client, "post", "create", "-title", "runbook", "-body", "steps", "-tag", "go, ops", "-group", "dev", "-notice=false")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var post docbase.Post
+	if err := json.Unmarshal([]byte(output), &post); err != nil {
+		t.Fatal(err)
+	}
+	stored, ok := server.Post(post.ID)
+	if !ok || stored.Title != "runbook" || stored.Body != "steps" || stored.Scope != docbase.ScopeGroup {
+		t.Errorf("post = %+v, want runbook in the group scope", stored)
+	}
+	var tags []string
+	for _, tag := range stored.Tags {
+		tags = append(tags, tag.Name)
+	}
+	if !equalStrings(tags, []string{"go", "ops"}) {
+		t.Errorf("tags = %q, want go and ops", tags)
+	}
+	if len(stored.Groups) != 1 || stored.Groups[0].ID != dev.ID {
+		t.Errorf("groups = %+v, want dev", stored.Groups)
+	}
+	recorder.recorded()
+
+	output, err = runCommand(t, client, "post", "list", "-q", "tag:go", "-per-page", "5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var posts []docbase.Post
+	if err := json.Unmarshal([]byte(output), &posts); err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 || posts[0].ID != post.ID {
+		t.Errorf("posts = %+v, want the created one", posts)
+	}
+	want := []string{"GET /teams/example/posts?page=1&per_page=5&q=tag%3Ago"}
+	if got := recorder.recorded(); !equalStrings(got, want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+
+	if _, err := runCommand(t, client, "post", "edit", "-title", "renamed", "-tag", "", fmt.Sprint(post.ID)); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := server.Post(post.ID); stored.Title != "renamed" || stored.Body != "steps" || len(stored.Tags) != 0 {
+		t.Errorf("post = %+v, want renamed without tags", stored)
+	}
+
+	if _, err := runCommand(t, client, "post", "archive", fmt.Sprint(post.ID)); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := server.Post(post.ID); !stored.Archived {
+		t.Errorf("post = %+v, want archived", stored)
+	}
+	if _, err := runCommand(t, client, "post", "delete", fmt.Sprint(post.ID)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := server.Post(post.ID); ok {
+		t.Error("post is not deleted")
+	}
+	recorder.recorded()
+
+	for _, args := range [][]string{
+		{"post", "create", "-body", "steps"},
+		{"post", "create", "-title", "runbook"},
+		{"post", "create", "-title", "runbook", "-body", "steps", "-body-file", "body.md"},
+		{"post", "create", "-title", "runbook", "-body", "steps", "-published-at", "yesterday"},
+		{"post", "get"},
+		{"post", "get", "x"},
+		{"post", "edit", "-title", "renamed"},
+		{"post", "archive", "1", "2"},
+		{"post", "list", "-per-page", "many"},
+	} {
+		if _, err := runCommand(t, client, args...); err == nil {
+			t.Errorf("%q: error = nil, want an error", args)
+		}
+		if got := recorder.recorded(); len(got) > 0 {
+			t.Errorf("%q: requests = %q, want none", args, got)
+		}
+	}
+}
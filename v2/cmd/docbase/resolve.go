@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+// resolveGroupIDs gets IDs of groups specified by IDs or names.
+func resolveGroupIDs(ctx context.Context, client *docbase.Client, groups []string) ([]docbase.GroupID, error) {
+	ids := make([]docbase.GroupID, 0, len(groups))
+	for _, g := range groups {
+		if id, err := strconv.ParseInt(g, 10, 64); err == nil {
+			ids = append(ids, docbase.GroupID(id))
+			continue
+		}
+		group, err := client.Group.FindByName(g).Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, group.ID)
+	}
+	return ids, nil
+}
+
+// resolveUserIDs gets IDs of users specified by IDs or usernames.
+func resolveUserIDs(ctx context.Context, client *docbase.Client, users []string) ([]docbase.UserID, error) {
+	var byName map[string]docbase.UserID
+	ids := make([]docbase.UserID, 0, len(users))
+	for _, u := range users {
+		if id, err := strconv.ParseInt(u, 10, 64); err == nil {
+			ids = append(ids, docbase.UserID(id))
+			continue
+		}
+		if byName == nil {
+			all, err := client.User.List().All(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("list users: %w", err)
+			}
+			byName = map[string]docbase.UserID{}
+			for _, user := range all {
+				byName[user.Username] = user.ID
+			}
+		}
+		id, ok := byName[u]
+		if !ok {
+			return nil, fmt.Errorf("%w: user %q", docbase.ErrNotFound, u)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/sync"
+)
+
+var syncCommand = command{
+	name:    "sync",
+	summary: "Reconcile group memberships with a roster (CSV or YAML)",
+	run:     runSync,
+}
+
+func runSync(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("sync [flags]")
+	rosterPath := flags.String("roster", "", "Roster file: CSV (username,group,...) or YAML (username: [group, ...])")
+	var groups listFlag
+	flags.Var(&groups, "groups", "Names of groups to reconcile (repeatable or comma-separated; default: groups in the roster)")
+	createGroups := flags.Bool("create-groups", false, "Create groups which do not exist")
+	dryRun := flags.Bool("dry-run", false, "Show the plan without applying it")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *rosterPath == "" {
+		return errors.New("-roster is required")
+	}
+
+	roster, err := readRoster(*rosterPath)
+	if err != nil {
+		return err
+	}
+	opts := sync.Options{Groups: groups, CreateGroups: *createGroups}
+	plan, err := sync.NewPlan(ctx, client, roster, opts)
+	if err != nil {
+		return err
+	}
+	for _, problem := range plan.Problems {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", problem)
+	}
+	if *dryRun {
+		for _, step := range plan.Steps {
+			fmt.Println(step)
+		}
+		return nil
+	}
+
+	failed := 0
+	for _, result := range plan.Apply(ctx, client) {
+		if result.Err != nil {
+			failed++
+			fmt.Printf("failed %s: %s\n", result.Step, result.Err)
+			continue
+		}
+		fmt.Println(result.Step)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d steps failed", failed, len(plan.Steps))
+	}
+	return nil
+}
+
+func readRoster(path string) (sync.Roster, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return sync.ReadYAML(file)
+	default:
+		return sync.ReadCSV(file)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+var tagCommand = command{
+	name: "tag",
+	subcommands: []command{
+		{name: "list", summary: "List tags", run: runTagList},
+	},
+}
+
+func runTagList(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("tag list [flags]")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	tags, _, err := client.Tag.List().Do(ctx)
+	if err != nil {
+		return err
+	}
+	return printResult(tags)
+}
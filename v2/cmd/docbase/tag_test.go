@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+func TestTagList(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	client, recorder, closeRecorder := recordedClient(t, server)
+	defer closeRecorder()
+	server.AddPost(docbase.Post{Title: "title", Body: "body", Tags: []docbase.Tag{{Name: "go"}}})
+
+	output, err := runCommand(t, client, "tag", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tags []docbase.Tag
+	if err := json.Unmarshal([]byte(output), &tags); err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0].Name != "go" {
+		t.Errorf("tags = %+v, want go", tags)
+	}
+	want := []string{"GET /teams/example/tags"}
+	if got := recorder.recorded(); !equalStrings(got, want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+
+	if _, err := runCommand(t, client, "tag", "list", "-unknown"); err == nil {
+		t.Error("error = nil, want an error for an unknown flag")
+	}
+}
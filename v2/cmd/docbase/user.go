@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+var userCommand = command{
+	name: "user",
+	subcommands: []command{
+		{name: "list", summary: "List users", run: runUserList},
+	},
+}
+
+func runUserList(ctx context.Context, client *docbase.Client, args []string) error {
+	flags := newFlagSet("user list [flags]")
+	query := flags.String("q", "", "Query to filter users by name or username")
+	includeGroups := flags.Bool("include-groups", false, "Include groups of each user")
+	page := flags.Int64("page", 1, "Page to get")
+	perPage := flags.Int64("per-page", 20, "Number of users in a page (max: 100)")
+	all := flags.Bool("all", false, "Get users in all pages from the page")
+	limit := flags.Int64("limit", 0, "Maximum number of users to get with -all")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	list := client.User.List().Page(*page).PerPage(*perPage).Limit(*limit).IncludeUserGroups(*includeGroups)
+	if *query != "" {
+		list.Query(*query)
+	}
+	if *all {
+		users, err := list.All(ctx)
+		if err != nil {
+			return err
+		}
+		return printResult(users)
+	}
+	users, _, err := list.Do(ctx)
+	if err != nil {
+		return err
+	}
+	return printResult(users)
+}
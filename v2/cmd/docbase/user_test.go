@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+func TestUserList(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	client, recorder, closeRecorder := recordedClient(t, server)
+	defer closeRecorder()
+	for _, name := range []string{"alice", "bob", "carol"} {
+		server.AddUser(docbase.User{Name: name, Username: name})
+	}
+
+	output, err := runCommand(t, client, "user", "list", "-all", "-per-page", "2", "-include-groups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var users []docbase.User
+	if err := json.Unmarshal([]byte(output), &users); err != nil {
+		t.Fatal(err)
+	}
+	// The owner of the fake server is listed too.
+	if len(users) != 4 {
+		t.Errorf("users = %+v, want 4 of them", users)
+	}
+	// Users have no link to the next page, so a full page makes it request
+	// the next page.
+	want := []string{
+		"GET /teams/example/users?include_user_groups=true&page=1&per_page=2",
+		"GET /teams/example/users?include_user_groups=true&page=2&per_page=2",
+		"GET /teams/example/users?include_user_groups=true&page=3&per_page=2",
+	}
+	if got := recorder.recorded(); !equalStrings(got, want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+
+	if _, err := runCommand(t, client, "user", "list", "-page", "first"); err == nil {
+		t.Error("error = nil, want an error for an invalid page")
+	}
+	if got := recorder.recorded(); len(got) > 0 {
+		t.Errorf("requests = %q, want none", got)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// newFlagSet creates a flag set for a command whose usage is like
+// "post get [flags] <id>".
+func newFlagSet(usage string) *flag.FlagSet {
+	var name []string
+	for _, field := range strings.Fields(usage) {
+		if strings.HasPrefix(field, "[") || strings.HasPrefix(field, "<") {
+			break
+		}
+		name = append(name, field)
+	}
+	flags := flag.NewFlagSet(strings.Join(name, " "), flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: docbase %s\n", usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// isSet checks whether the flag is specified in the arguments.
+func isSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// listFlag is a flag which can be repeated or separated by commas.
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// readBody reads a body from the text, or from the file if the text is empty.
+// The file "-" means the standard input.
+func readBody(text, file string) (string, error) {
+	switch {
+	case text != "" && file != "":
+		return "", errors.New("-body and -body-file cannot be specified together")
+	case file == "-":
+		b, err := ioutil.ReadAll(os.Stdin)
+		return string(b), err
+	case file != "":
+		b, err := ioutil.ReadFile(file)
+		return string(b), err
+	}
+	return text, nil
+}
+
+// parseID parses the only argument as an ID.
+func parseID(args []string, name string) (int64, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("%s is required", name)
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", name, args[0])
+	}
+	return id, nil
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProfileName is the name of the profile used if no profile is
+// specified.
+const DefaultProfileName = "default"
+
+// ErrInsecurePermission is returned when a file which contains a token or a
+// token command can be accessed by others than the owner.
+var ErrInsecurePermission = errors.New("insecure permission")
+
+// Config is the content of the config file.
+type Config struct {
+	// DefaultProfile is the name of the profile used if no profile is
+	// specified. It defaults to DefaultProfileName.
+	DefaultProfile string             `yaml:"default_profile,omitempty"`
+	Profiles       map[string]Profile `yaml:"profiles,omitempty"`
+}
+
+// Profile specifies a team and how to get an access token for it.
+// The token is got from Token, TokenFile or TokenCommand, in this order.
+type Profile struct {
+	Domain string `yaml:"domain,omitempty"`
+	// Token is the access token. Prefer TokenFile or TokenCommand to keep it
+	// out of the config file.
+	Token string `yaml:"token,omitempty"`
+	// TokenFile is a path to the file which contains the access token.
+	// "~/" at the beginning is expanded to the home directory.
+	TokenFile string `yaml:"token_file,omitempty"`
+	// TokenCommand is a shell command which prints the access token.
+	TokenCommand string `yaml:"token_command,omitempty"`
+	// BaseURL is the base URL of the API. It defaults to the public API.
+	BaseURL string `yaml:"base_url,omitempty"`
+}
+
+// Path gets the path of the config file: "docbase/config.yaml" in
+// $XDG_CONFIG_HOME, or in ~/.config if it is not set.
+func Path() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "docbase", "config.yaml"), nil
+}
+
+// Load loads the config file. If it does not exist, Load returns an empty
+// config. If the file contains a token or a token command and can be accessed
+// by others than the owner, Load returns an error wrapping
+// ErrInsecurePermission.
+func Load(path string) (*Config, error) {
+	content, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return &Config{}, nil
+	case err != nil:
+		return nil, err
+	}
+	var config Config
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	for _, profile := range config.Profiles {
+		// A token command is also sensitive: it is run by the tool, and it
+		// may contain a secret in its arguments.
+		if profile.Token != "" || profile.TokenCommand != "" {
+			if err := checkPermission(path); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	return &config, nil
+}
+
+// Profile gets the profile by the name, overridden by the environment
+// variables. If the name is empty, $DOCBASE_PROFILE or the default profile is
+// used. A profile specified explicitly must exist, but the default profile
+// may not exist if the environment variables provide the settings.
+func (c *Config) Profile(name string) (Profile, error) {
+	if name == "" {
+		name = os.Getenv("DOCBASE_PROFILE")
+	}
+	explicit := name != ""
+	if name == "" {
+		name = c.DefaultProfile
+		explicit = name != ""
+	}
+	if name == "" {
+		name = DefaultProfileName
+	}
+	profile, ok := c.Profiles[name]
+	if !ok && explicit {
+		return Profile{}, fmt.Errorf("profile %q is not found", name)
+	}
+	return profile.withEnv(), nil
+}
+
+// withEnv overrides the profile with the environment variables.
+func (p Profile) withEnv() Profile {
+	if v := os.Getenv("DOCBASE_DOMAIN"); v != "" {
+		p.Domain = v
+	}
+	if v := os.Getenv("DOCBASE_TOKEN_FILE"); v != "" {
+		p.Token, p.TokenFile, p.TokenCommand = "", v, ""
+	}
+	if v := os.Getenv("DOCBASE_TOKEN"); v != "" {
+		p.Token, p.TokenFile, p.TokenCommand = v, "", ""
+	}
+	if v := os.Getenv("DOCBASE_BASE_URL"); v != "" {
+		p.BaseURL = v
+	}
+	return p
+}
+
+// ResolveToken gets the access token from Token, TokenFile or TokenCommand.
+func (p Profile) ResolveToken(ctx context.Context) (string, error) {
+	switch {
+	case p.Token != "":
+		return p.Token, nil
+	case p.TokenFile != "":
+		return readTokenFile(p.TokenFile)
+	case p.TokenCommand != "":
+		return runTokenCommand(ctx, p.TokenCommand)
+	}
+	return "", errors.New("access token is not specified")
+}
+
+// NewClient creates a client authenticated with the access token.
+func (p Profile) NewClient(ctx context.Context) (*docbase.Client, error) {
+	if p.Domain == "" {
+		return nil, errors.New("team domain is not specified")
+	}
+	token, err := p.ResolveToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client := docbase.NewAuthClient(p.Domain, token)
+	if p.BaseURL != "" {
+		if _, err := client.WithBaseURL(p.BaseURL); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+// NewClient creates a client with the profile in the config file (see Path),
+// overridden by the environment variables. If the name is empty,
+// $DOCBASE_PROFILE or the default profile is used.
+func NewClient(ctx context.Context, profile string) (*docbase.Client, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	config, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := config.Profile(profile)
+	if err != nil {
+		return nil, err
+	}
+	return p.NewClient(ctx)
+}
+
+func readTokenFile(path string) (string, error) {
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[2:])
+	}
+	if err := checkPermission(path); err != nil {
+		return "", err
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
+
+func runTokenCommand(ctx context.Context, command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("token command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", errors.New("token command printed nothing")
+	}
+	return token, nil
+}
+
+// checkPermission checks that the file can be read only by the owner.
+// It is skipped on Windows, where the permission bits are not meaningful.
+func checkPermission(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		return fmt.Errorf("%w: %s has mode %04o; run chmod 600 %s", ErrInsecurePermission, path, perm, path)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/config"
+)
+
+func TestLoad(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are not checked on Windows")
+	}
+	for _, test := range []struct {
+		title   string
+		content string
+		mode    os.FileMode
+		wantErr error
+	}{
+		{title: "token", content: "profiles:\n  work:\n    token: secret\n", mode: 0600},
+		{title: "readable token", content: "profiles:\n  work:\n    token: secret\n", mode: 0644, wantErr: config.ErrInsecurePermission},
+		{title: "token command", content: "profiles:\n  work:\n    token_command: pass show docbase\n", mode: 0600},
+		{title: "readable token command", content: "profiles:\n  work:\n    token_command: pass show docbase\n", mode: 0644, wantErr: config.ErrInsecurePermission},
+		{title: "writable token command", content: "profiles:\n  work:\n    token_command: pass show docbase\n", mode: 0620, wantErr: config.ErrInsecurePermission},
+		{title: "token file", content: "profiles:\n  work:\n    token_file: ~/work.token\n", mode: 0644},
+		{title: "domain only", content: "profiles:\n  work:\n    domain: example\n", mode: 0644},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "docbase-config-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "config.yaml")
+			if err := ioutil.WriteFile(path, []byte(test.content), test.mode); err != nil {
+				t.Fatal(err)
+			}
+			// The mode given to WriteFile is masked by umask.
+			if err := os.Chmod(path, test.mode); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = config.Load(path)
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Errorf("error = %v, want %v", err, test.wantErr)
+				}
+			} else if err != nil {
+				t.Errorf("error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	cfg, err := config.Load(filepath.Join(os.TempDir(), "docbase-config-missing", "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Profiles) != 0 {
+		t.Errorf("profiles = %v, want none", cfg.Profiles)
+	}
+}
+
+func TestResolveToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docbase-config-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "work.token")
+	if err := ioutil.WriteFile(tokenFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	readable := filepath.Join(dir, "readable.token")
+	if err := ioutil.WriteFile(readable, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(readable, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		title   string
+		profile config.Profile
+		want    string
+		wantErr bool
+	}{
+		{title: "token", profile: config.Profile{Token: "plain", TokenFile: tokenFile}, want: "plain"},
+		{title: "token file", profile: config.Profile{TokenFile: tokenFile, TokenCommand: "echo command"}, want: "from-file"},
+		{title: "readable token file", profile: config.Profile{TokenFile: readable}, wantErr: runtime.GOOS != "windows"},
+		{title: "token command", profile: config.Profile{TokenCommand: "echo from-command"}, want: "from-command"},
+		{title: "empty token command", profile: config.Profile{TokenCommand: "true"}, wantErr: true},
+		{title: "none", profile: config.Profile{}, wantErr: true},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			if runtime.GOOS == "windows" && test.profile.TokenCommand != "" {
+				t.Skip("token commands are run with sh in this test")
+			}
+			got, err := test.profile.ResolveToken(context.Background())
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("error = %v, want error: %t", err, test.wantErr)
+			}
+			if !test.wantErr && got != test.want {
+				t.Errorf("token = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+/*
+Package config resolves the team domain and the access token to create a
+Docbase client, from profiles in a config file and environment variables.
+
+The config file is "docbase/config.yaml" in $XDG_CONFIG_HOME (or ~/.config):
+
+	default_profile: work
+	profiles:
+	  work:
+	    domain: example
+	    token_file: ~/.config/docbase/work.token
+	  oss:
+	    domain: example-oss
+	    token_command: pass show docbase/oss
+
+Environment variables override the profile:
+
+	DOCBASE_PROFILE     name of the profile to use
+	DOCBASE_DOMAIN      team domain
+	DOCBASE_TOKEN       access token
+	DOCBASE_TOKEN_FILE  file which contains the access token
+	DOCBASE_BASE_URL    base URL of the API
+
+Files which contain tokens must not be readable by others than the owner.
+*/
+package config
@@ -1,7 +1,13 @@
 package docbase
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
 )
 
 // attachmentService provides access to the installation related functions
@@ -10,37 +16,197 @@ type attachmentService service
 
 // Upload attachments for a domain.
 //
+// Contents are streamed to the API without being loaded into memory all at
+// once. Many or large attachments are split into several requests.
+//
 // Docbase API docs: https://help.docbase.io/posts/225804
 func (s *attachmentService) Upload() *attachmentUploadDoer {
-	return &attachmentUploadDoer{client: s.client}
-}
-
-type attachmentUploadPayload struct {
-	Name    string `json:"name"`
-	Content []byte `json:"content"`
+	return &attachmentUploadDoer{
+		client:        s.client,
+		maxFileSize:   defaultMaxFileSize,
+		maxBatchSize:  defaultMaxBatchSize,
+		maxBatchFiles: defaultMaxBatchFiles,
+	}
 }
 
 type attachmentUploadDoer struct {
-	client   *Client
-	payloads []attachmentUploadPayload
+	client        *Client
+	payloads      []*uploadPayload
+	maxFileSize   int64
+	maxBatchSize  int64
+	maxBatchFiles int
 }
 
+// AddPayload adds an attachment with the content.
 func (d *attachmentUploadDoer) AddPayload(name string, content []byte) *attachmentUploadDoer {
-	d.payloads = append(d.payloads, attachmentUploadPayload{Name: name, Content: content})
+	return d.AddReader(name, bytes.NewReader(content))
+}
+
+// AddReader adds an attachment whose content is read from r while uploading.
+//
+// If r is an io.Seeker (e.g. *os.File), its size is checked before sending
+// and it is read again from the current offset when the request is retried.
+// Otherwise the size is checked while sending. The caller is responsible for
+// closing r after Do returns.
+func (d *attachmentUploadDoer) AddReader(name string, r io.Reader) *attachmentUploadDoer {
+	d.payloads = append(d.payloads, newUploadPayload(name, r))
+	return d
+}
+
+// MaxFileSize sets the maximum size of each attachment in bytes.
+// It defaults to 100 MiB.
+func (d *attachmentUploadDoer) MaxFileSize(size int64) *attachmentUploadDoer {
+	d.maxFileSize = size
+	return d
+}
+
+// MaxBatchSize sets the maximum total size of attachments uploaded in a
+// request, in bytes before encoding. An attachment larger than it is uploaded
+// in a request alone. It defaults to 100 MiB.
+func (d *attachmentUploadDoer) MaxBatchSize(size int64) *attachmentUploadDoer {
+	d.maxBatchSize = size
+	return d
+}
+
+// MaxBatchFiles sets the maximum number of attachments uploaded in a request.
+// It defaults to 20.
+func (d *attachmentUploadDoer) MaxBatchFiles(n int) *attachmentUploadDoer {
+	d.maxBatchFiles = n
 	return d
 }
 
+// Do uploads the attachments, and returns them in the order they are added.
+// The returned Response is the one for the last request.
+//
+// If a request fails, Do returns the attachments uploaded before it with the
+// error. If an attachment is known to be larger than MaxFileSize, Do returns
+// an error wrapping ErrAttachmentTooLarge without sending any request.
 func (d *attachmentUploadDoer) Do(ctx context.Context) ([]Attachment, *Response, error) {
-	req, err := d.client.NewRequest("POST", "attachments", d.payloads)
+	for _, p := range d.payloads {
+		if p.size > d.maxFileSize {
+			return nil, nil, fmt.Errorf("%w: %q has %d bytes (max: %d)", ErrAttachmentTooLarge, p.name, p.size, d.maxFileSize)
+		}
+	}
+
+	var (
+		uploaded []Attachment
+		resp     *Response
+	)
+	for _, batch := range d.batches() {
+		var (
+			g   []Attachment
+			err error
+		)
+		resp, err = d.do(ctx, batch, &g)
+		if err != nil {
+			return uploaded, resp, err
+		}
+		uploaded = append(uploaded, g...)
+	}
+	return uploaded, resp, nil
+}
+
+// batches splits the payloads into batches within the limits.
+// The size of a payload whose size is unknown is regarded as maxFileSize.
+func (d *attachmentUploadDoer) batches() [][]*uploadPayload {
+	var (
+		batches [][]*uploadPayload
+		batch   []*uploadPayload
+		size    int64
+	)
+	for _, p := range d.payloads {
+		psize := p.size
+		if psize < 0 {
+			psize = d.maxFileSize
+		}
+		if len(batch) > 0 && (len(batch) >= d.maxBatchFiles || size+psize > d.maxBatchSize) {
+			batches = append(batches, batch)
+			batch, size = nil, 0
+		}
+		batch = append(batch, p)
+		size += psize
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+func (d *attachmentUploadDoer) do(ctx context.Context, batch []*uploadPayload, v interface{}) (*Response, error) {
+	req, err := d.client.NewRequest("POST", "attachments", nil)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	body := &uploadBody{payloads: batch, maxFileSize: d.maxFileSize}
+	defer body.close()
+	if req.Body, err = body.open(); err != nil {
+		return nil, err
+	}
+	req.ContentLength = body.length()
+	if body.rewindable() {
+		req.GetBody = body.open
 	}
+	req.Header.Set("Content-Type", contentTypeJSON)
+	return d.client.Do(ctx, req, v)
+}
+
+// Download downloads an attachment from its URL, like Attachment.URL or a URL
+// found in a post body. Since the request is authenticated with the access
+// token, only https URLs on Docbase hosts are accepted, and the token is not
+// sent on redirects to other hosts (see TokenTransport).
+func (s *attachmentService) Download(urlStr string) *attachmentDownloadDoer {
+	return &attachmentDownloadDoer{client: s.client, url: urlStr}
+}
+
+// DownloadAttachment downloads the attachment.
+func (s *attachmentService) DownloadAttachment(attachment Attachment) *attachmentDownloadDoer {
+	return s.Download(attachment.URL)
+}
 
-	var g []Attachment
-	resp, err := d.client.Do(ctx, req, &g)
+// IsAttachmentURL reports whether the URL points to an attachment in Docbase,
+// which can be downloaded by Download. The URL must be https, unless it is on
+// the API server with the same scheme as Client.BaseURL (e.g. a test server).
+func (s *attachmentService) IsAttachmentURL(urlStr string) bool {
+	u, err := url.Parse(urlStr)
 	if err != nil {
-		return nil, resp, err
+		return false
 	}
+	base := s.client.BaseURL
+	switch {
+	case u.Scheme == base.Scheme && strings.EqualFold(u.Host, base.Host):
+	case u.Scheme == "https" && s.client.isDocbaseHost(u.Host):
+	default:
+		return false
+	}
+	return strings.HasPrefix(u.Path, "/uploads/") || strings.HasPrefix(u.Path, "/file_attachments/")
+}
+
+// isDocbaseHost reports whether the host belongs to Docbase or the API server
+// which the client talks to.
+func (c *Client) isDocbaseHost(host string) bool {
+	if strings.EqualFold(host, c.BaseURL.Host) {
+		return true
+	}
+	host = strings.ToLower(host)
+	return host == "docbase.io" || strings.HasSuffix(host, ".docbase.io")
+}
 
-	return g, resp, nil
+type attachmentDownloadDoer struct {
+	client *Client
+	url    string
+}
+
+// Do streams the content of the attachment to w.
+func (d *attachmentDownloadDoer) Do(ctx context.Context, w io.Writer) (*Response, error) {
+	if !d.client.Attachment.IsAttachmentURL(d.url) {
+		return nil, fmt.Errorf("%q is not a URL of a Docbase attachment", d.url)
+	}
+	req, err := http.NewRequest("GET", d.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if d.client.UserAgent != "" {
+		req.Header.Set("User-Agent", d.client.UserAgent)
+	}
+	return d.client.Do(ctx, req, w)
 }
@@ -0,0 +1,156 @@
+package docbase_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+func TestIsAttachmentURL(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	for _, test := range []struct {
+		url  string
+		want bool
+	}{
+		{url: "https://image.docbase.io/uploads/a.png", want: true},
+		{url: "https://docbase.io/file_attachments/a.pdf", want: true},
+		{url: "http://image.docbase.io/uploads/a.png", want: false},
+		{url: "https://image.docbase.io/other/a.png", want: false},
+		{url: "https://docbase.io.example.com/uploads/a.png", want: false},
+		{url: "https://example.com/uploads/a.png", want: false},
+		{url: server.URL + "/uploads/1/a.png", want: true},
+		{url: strings.Replace(server.URL, "http://", "https://", 1) + "/uploads/1/a.png", want: true},
+		{url: "/uploads/1/a.png", want: false},
+	} {
+		if got := client.Attachment.IsAttachmentURL(test.url); got != test.want {
+			t.Errorf("IsAttachmentURL(%q): expect %v, but got %v", test.url, test.want, got)
+		}
+	}
+}
+
+// tokenRecorder records access tokens sent to it, and serves "stored".
+type tokenRecorder struct {
+	mu     sync.Mutex
+	tokens []string
+}
+
+func (h *tokenRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.tokens = append(h.tokens, r.Header.Get("X-DocBaseToken"))
+	h.mu.Unlock()
+	_, _ = w.Write([]byte("stored"))
+}
+
+func (h *tokenRecorder) recorded() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string{}, h.tokens...)
+}
+
+func TestAttachmentDownload(t *testing.T) {
+	for _, test := range []struct {
+		title      string
+		redirect   string // "same" or "other" host to redirect downloads to
+		path       string // path of the attachment URL; the uploaded one if empty
+		want       string
+		wantErr    bool
+		wantTokens []string // tokens sent to the redirected location
+	}{
+		{title: "direct", want: "content"},
+		{title: "same host", redirect: "same", want: "stored", wantTokens: []string{docbasetest.DefaultToken}},
+		{title: "other host", redirect: "other", want: "stored", wantTokens: []string{""}},
+		{title: "not found", path: "/uploads/0/missing.png", wantErr: true},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			server := docbasetest.NewServer()
+			defer server.Close()
+			attachments, _, err := server.Client().Attachment.Upload().AddPayload("a.png", []byte("content")).Do(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			storage := &tokenRecorder{}
+			other := httptest.NewServer(storage)
+			defer other.Close()
+			mux := http.NewServeMux()
+			mux.Handle("/storage/", storage)
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				switch test.redirect {
+				case "same":
+					http.Redirect(w, r, "/storage"+r.URL.Path, http.StatusFound)
+				case "other":
+					http.Redirect(w, r, other.URL+"/storage"+r.URL.Path, http.StatusFound)
+				default:
+					server.ServeHTTP(w, r)
+				}
+			})
+			api := httptest.NewServer(mux)
+			defer api.Close()
+			client, err := docbase.NewAuthClient(server.Domain, server.Token).WithBaseURL(api.URL + "/")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			path := test.path
+			if path == "" {
+				u, err := url.Parse(attachments[0].URL)
+				if err != nil {
+					t.Fatal(err)
+				}
+				path = u.Path
+			}
+			var buf bytes.Buffer
+			_, err = client.Attachment.Download(api.URL+path).Do(context.Background(), &buf)
+			if test.wantErr {
+				var errResp *docbase.ErrorResponse
+				if !errors.As(err, &errResp) || errResp.Response.StatusCode != http.StatusNotFound {
+					t.Fatalf("expect a not found error, but got %v", err)
+				}
+				if errResp.Value == "" && len(errResp.Messages) == 0 {
+					t.Errorf("expect the error to have the message in the response, but got %v", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != test.want {
+				t.Errorf("expect content %q, but got %q", test.want, got)
+			}
+			if got := storage.recorded(); !equalStrings(got, test.wantTokens) {
+				t.Errorf("expect tokens %q to be sent to the storage, but got %q", test.wantTokens, got)
+			}
+		})
+	}
+}
+
+func TestAttachmentDownloadRejected(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	var buf bytes.Buffer
+	if _, err := server.Client().Attachment.Download("http://image.docbase.io/uploads/a.png").Do(context.Background(), &buf); err == nil {
+		t.Error("expect an error for an http URL, but got nil")
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
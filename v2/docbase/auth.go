@@ -1,9 +1,16 @@
 package docbase
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+)
 
 // TokenTransport is an http.RoundTripper that authenticates all requests
 // with the provided access token.
+//
+// The token is not sent on redirects to other hosts or schemes than the ones
+// the request was first sent to (e.g. a storage of attachments), not to leak
+// it.
 type TokenTransport struct {
 	Token string
 
@@ -27,7 +34,11 @@ func (t *TokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		req2.Header[k] = append([]string(nil), s...)
 	}
 
-	req2.Header.Set(headerToken, t.Token)
+	if redirectedAway(req) {
+		req2.Header.Del(headerToken)
+	} else {
+		req2.Header.Set(headerToken, t.Token)
+	}
 	return t.transport().RoundTrip(req2)
 }
 
@@ -37,6 +48,16 @@ func (t *TokenTransport) Client() *http.Client {
 	return &http.Client{Transport: t}
 }
 
+// redirectedAway reports whether the request is made by a redirect to
+// another host or scheme than the ones the first request was sent to.
+func redirectedAway(req *http.Request) bool {
+	first := req
+	for first.Response != nil && first.Response.Request != nil {
+		first = first.Response.Request
+	}
+	return first.URL.Scheme != req.URL.Scheme || !strings.EqualFold(first.URL.Host, req.URL.Host)
+}
+
 func (t *TokenTransport) transport() http.RoundTripper {
 	if t.Transport != nil {
 		return t.Transport
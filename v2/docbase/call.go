@@ -0,0 +1,59 @@
+package docbase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Call sends a request to an endpoint which this package does not cover yet,
+// and decodes the response into v in the same way as Do: the rate limit,
+// retries and errors are handled as with the other methods.
+//
+// The path is relative to the team (e.g. "posts/1/comments"), or to the base
+// URL if it starts with a slash (e.g. "/teams"). It is sent as it is escaped
+// (e.g. "tags/a%2Fb"), and cannot have "." or ".." segments, not to escape
+// the team. It can have query parameters, which are merged with query. If body
+// is not nil, it is encoded in JSON; use json.RawMessage to send JSON as it
+// is. If method is empty, GET is used.
+func (c *Client) Call(ctx context.Context, method, path string, query url.Values, body, v interface{}) (*Response, error) {
+	if method == "" {
+		method = http.MethodGet
+	}
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "" || u.Host != "" {
+		return nil, fmt.Errorf("path %q must not be an absolute URL", path)
+	}
+	escaped := u.EscapedPath()
+	for _, segment := range strings.Split(escaped, "/") {
+		if unescaped, err := url.PathUnescape(segment); err == nil && (unescaped == "." || unescaped == "..") {
+			return nil, fmt.Errorf("path %q must not have %q segments", path, unescaped)
+		}
+	}
+	params := u.Query()
+	for key, values := range query {
+		for _, value := range values {
+			params.Add(key, value)
+		}
+	}
+
+	var req *http.Request
+	if strings.HasPrefix(escaped, "/") {
+		if !strings.HasSuffix(c.BaseURL.Path, "/") {
+			return nil, fmt.Errorf("BaseURL must have a trailing slash, but %q does not", c.BaseURL)
+		}
+		req, err = c.newRequest(strings.ToUpper(method), strings.TrimPrefix(escaped, "/"), body)
+	} else {
+		req, err = c.NewRequest(strings.ToUpper(method), escaped, body)
+	}
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = params.Encode()
+	return c.Do(ctx, req, v)
+}
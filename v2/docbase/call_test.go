@@ -0,0 +1,121 @@
+package docbase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+// pathRecorder records escaped paths and queries of requests, and passes them
+// to the fake server.
+type pathRecorder struct {
+	server *docbasetest.Server
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func (h *pathRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.requests = append(h.requests, r.Method+" "+r.URL.EscapedPath()+"?"+r.URL.RawQuery)
+	h.mu.Unlock()
+	h.server.ServeHTTP(w, r)
+}
+
+func (h *pathRecorder) recorded() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string{}, h.requests...)
+}
+
+func TestCall(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	server.AddPost(docbase.Post{Title: "first", Body: "body", Tags: []docbase.Tag{{Name: "go"}}})
+	server.AddPost(docbase.Post{Title: "second", Body: "body"})
+	handler := &pathRecorder{server: server}
+	recorder := httptest.NewServer(handler)
+	defer recorder.Close()
+	client, err := docbase.NewAuthClient(server.Domain, server.Token).WithBaseURL(recorder.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	t.Run("get with a query", func(t *testing.T) {
+		var res struct {
+			Posts []docbase.Post `json:"posts"`
+		}
+		if _, err := client.Call(ctx, "", "posts?per_page=10", url.Values{"q": {"tag:go"}}, nil, &res); err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Posts) != 1 || res.Posts[0].Title != "first" {
+			t.Errorf("posts = %+v, want the first one", res.Posts)
+		}
+		requests := handler.recorded()
+		if want := "GET /teams/example/posts?per_page=10&q=tag%3Ago"; requests[len(requests)-1] != want {
+			t.Errorf("request = %q, want %q", requests[len(requests)-1], want)
+		}
+	})
+
+	t.Run("post with a body", func(t *testing.T) {
+		var post docbase.Post
+		body := json.RawMessage(`{"title":"created","body":"raw"}`)
+		if _, err := client.Call(ctx, "post", "posts", nil, body, &post); err != nil {
+			t.Fatal(err)
+		}
+		stored, ok := server.Post(post.ID)
+		if !ok || stored.Title != "created" || stored.Body != "raw" {
+			t.Errorf("post = %+v, want created/raw", stored)
+		}
+	})
+
+	t.Run("leading slash", func(t *testing.T) {
+		var teams []docbase.Team
+		if _, err := client.Call(ctx, "GET", "/teams", nil, nil, &teams); err != nil {
+			t.Fatal(err)
+		}
+		if len(teams) != 1 || teams[0].Domain != server.Domain {
+			t.Errorf("teams = %+v, want %q", teams, server.Domain)
+		}
+	})
+
+	t.Run("escaped segment", func(t *testing.T) {
+		_, err := client.Call(ctx, "GET", "tags/a%2Fb", nil, nil, nil)
+		if !errors.Is(err, docbase.ErrNotFound) {
+			t.Errorf("error = %v, want not found", err)
+		}
+		requests := handler.recorded()
+		if want := "GET /teams/example/tags/a%2Fb?"; requests[len(requests)-1] != want {
+			t.Errorf("request = %q, want %q", requests[len(requests)-1], want)
+		}
+	})
+
+	for _, path := range []string{
+		"../other/posts",
+		"posts/../../other/posts",
+		"/teams/example/../other",
+		"posts/%2e%2e/x",
+		"./posts",
+		"https://example.com/teams",
+		"//example.com/teams",
+	} {
+		t.Run("rejected "+path, func(t *testing.T) {
+			before := len(handler.recorded())
+			if _, err := client.Call(ctx, "GET", path, nil, nil, nil); err == nil {
+				t.Error("error = nil, want an error")
+			}
+			if after := len(handler.recorded()); after != before {
+				t.Errorf("%d requests are sent, want none", after-before)
+			}
+		})
+	}
+}
@@ -12,7 +12,6 @@ import (
 	"path"
 	"reflect"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/google/go-querystring/query"
@@ -37,6 +36,7 @@ var (
 	baseURL = url.URL{
 		Scheme: scheme,
 		Host:   host,
+		Path:   "/",
 	}
 )
 
@@ -45,11 +45,31 @@ type Client struct {
 	domain string       // DocBase User Domain.
 	client *http.Client // HTTP client used to communicate with the API.
 
+	// Base URL for API requests. Defaults to the public DocBase API, but can be
+	// set to a local server, a proxy or an API gateway. BaseURL should always
+	// be specified with a trailing slash.
+	BaseURL *url.URL
+
 	// User agent used when communicating with the Docbase API.
 	UserAgent string
 
-	rateMu    sync.Mutex
-	rateLimit Rate // Rate limits for the client as determined by the most recent API calls.
+	rate *rateState // Rate limits for the client as determined by the most recent API calls.
+
+	// WaitRateLimit makes the client wait until the rate limit is reset and
+	// retry the request, instead of returning *RateLimitError.
+	WaitRateLimit bool
+
+	// OnRateLimitWait is called when the client starts waiting for the rate
+	// limit to be reset, if WaitRateLimit is true.
+	OnRateLimitWait func(req *http.Request, rate Rate, wait time.Duration)
+
+	// RetryPolicy specifies how to retry requests which failed transiently.
+	// If it is nil, requests will not be retried.
+	RetryPolicy *RetryPolicy
+
+	// RateLimiter paces requests to keep them from exceeding the rate limit.
+	// If it is nil, requests are sent without pacing.
+	RateLimiter *RateLimiter
 
 	common service // Reuse a single struct instead of allocating one for each service on the heap.
 
@@ -60,6 +80,7 @@ type Client struct {
 	Post       *postService
 	Comment    *commentService
 	Attachment *attachmentService
+	Team       *teamService
 }
 
 type service struct {
@@ -109,8 +130,15 @@ func NewClient(domain string, httpClient *http.Client) *Client {
 		httpClient = http.DefaultClient
 	}
 
-	c := &Client{client: httpClient, UserAgent: userAgent}
+	u := baseURL
+	c := &Client{client: httpClient, BaseURL: &u, UserAgent: userAgent, rate: new(rateState)}
 	c.domain = domain
+	c.init()
+	return c
+}
+
+// init sets up services of the client.
+func (c *Client) init() {
 	c.common.client = c
 	c.User = (*userService)(&c.common)
 	c.Tag = (*tagService)(&c.common)
@@ -118,7 +146,53 @@ func NewClient(domain string, httpClient *http.Client) *Client {
 	c.Post = (*postService)(&c.common)
 	c.Comment = (*commentService)(&c.common)
 	c.Attachment = (*attachmentService)(&c.common)
-	return c
+	c.Team = (*teamService)(&c.common)
+}
+
+// Domain gets the team domain which the client is bound to.
+func (c *Client) Domain() string {
+	return c.domain
+}
+
+// ForTeam returns a new client bound to another team domain.
+//
+// The new client has the same settings as c, and shares the http.Client
+// (and so the access token) and the rate limit state with c, since the rate
+// limit is counted per access token. Changing settings of either client
+// afterward does not affect the other.
+func (c *Client) ForTeam(domain string) *Client {
+	u := *c.BaseURL
+	t := &Client{
+		domain:          domain,
+		client:          c.client,
+		BaseURL:         &u,
+		UserAgent:       c.UserAgent,
+		rate:            c.rate,
+		WaitRateLimit:   c.WaitRateLimit,
+		OnRateLimitWait: c.OnRateLimitWait,
+		RetryPolicy:     c.RetryPolicy,
+		RateLimiter:     c.RateLimiter,
+	}
+	t.init()
+	return t
+}
+
+// WithBaseURL sets the base URL of the client and returns it.
+// It can contain a path prefix (e.g. "http://localhost:8080/docbase/"),
+// and a trailing slash will be added if it is missing.
+func (c *Client) WithBaseURL(baseURL string) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("base URL must be absolute, but %q is not", baseURL)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	c.BaseURL = u
+	return c, nil
 }
 
 // NewRequest creates an API request. A relative URL can be provided in urlStr,
@@ -127,7 +201,16 @@ func NewClient(domain string, httpClient *http.Client) *Client {
 // specified, the value pointed to by body is JSON encoded and included as the
 // request body.
 func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Request, error) {
-	u, err := baseURL.Parse(path.Join("teams", c.domain, urlStr))
+	if !strings.HasSuffix(c.BaseURL.Path, "/") {
+		return nil, fmt.Errorf("BaseURL must have a trailing slash, but %q does not", c.BaseURL)
+	}
+	return c.newRequest(method, path.Join("teams", c.domain, urlStr), body)
+}
+
+// newRequest creates an API request with a URL relative to the BaseURL,
+// which is not under the team domain.
+func (c *Client) newRequest(method, urlStr string, body interface{}) (*http.Request, error) {
+	u, err := c.BaseURL.Parse(urlStr)
 	if err != nil {
 		return nil, err
 	}
@@ -167,6 +250,9 @@ type Response struct {
 	Body bytes.Buffer
 	Meta
 	Rate
+
+	// Attempts is the number of attempts made to get the response.
+	Attempts int
 }
 
 // newResponse creates a new Response for the provided http.Response.
@@ -180,14 +266,50 @@ func newResponse(r *http.Response) *Response {
 // Do sends an API request and returns the API response. The API response is
 // JSON decoded and stored in the value pointed to by v, or returned as an
 // error if an API error has occurred. If v implements the io.Writer
-// interface, the raw response body will be written to v, without attempting to
-// first decode it. If rate limit is exceeded and reset time is in the future,
+// interface, the raw response body will be streamed to v, without attempting
+// to first decode it; Response.Body is left empty then. If rate limit is exceeded and reset time is in the future,
 // Do returns *RateLimitError immediately without making a network API call.
+// If WaitRateLimit is true, Do waits until the reset time and retries instead.
+// If RetryPolicy is set, Do retries the request failed transiently.
 //
 // The provided ctx must be non-nil. If it is canceled or times out,
 // ctx.Err() will be returned.
 func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Response, error) {
 	req = req.WithContext(ctx)
+	for attempts := 1; ; attempts++ {
+		response, err := c.do(ctx, req, v)
+		if response != nil {
+			response.Attempts = attempts
+		}
+		if rateErr, ok := err.(*RateLimitError); ok && c.WaitRateLimit {
+			if err := c.waitRateLimit(ctx, req, rateErr.Rate); err != nil {
+				return response, rateErr
+			}
+		} else if c.RetryPolicy.shouldRetry(req, response, err, attempts) {
+			if err := sleep(ctx, c.RetryPolicy.backoff(attempts)); err != nil {
+				return response, err
+			}
+		} else {
+			return response, err
+		}
+
+		req, err = rewindRequest(req)
+		if err != nil {
+			return response, err
+		}
+	}
+}
+
+// do sends an API request once.
+func (c *Client) do(ctx context.Context, req *http.Request, v interface{}) (*Response, error) {
+	var learned Rate // rate limit learned from the response
+	if l := c.RateLimiter; l != nil {
+		l.seed(c.rate.get())
+		if err := l.Wait(ctx); err != nil {
+			return nil, err
+		}
+		defer func() { l.done(learned) }()
+	}
 
 	// If we've hit rate limit, don't make further requests before Reset time.
 	if err := c.checkRateLimitBeforeDo(req); err != nil {
@@ -204,47 +326,70 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Res
 	defer resp.Body.Close()
 
 	response := newResponse(resp)
+	c.rate.set(response.Rate)
+	learned = response.Rate
+
+	if w, ok := v.(io.Writer); ok {
+		if err := checkResponse(resp); err != nil {
+			return response, err
+		}
+		// Stream the body without buffering it, since it may be large
+		// (e.g. an attachment). A failure after a part of the body is written
+		// is returned with the response, so that it is not retried.
+		if _, err := io.Copy(w, resp.Body); err != nil {
+			return response, err
+		}
+		return response, nil
+	}
+
 	var body bytes.Buffer
 	if _, err := io.Copy(&response.Body, io.TeeReader(resp.Body, &body)); err != nil {
 		return nil, err
 	}
 
-	c.rateMu.Lock()
-	c.rateLimit = response.Rate
-	c.rateMu.Unlock()
-
+	// The body has been read; let checkResponse read it again.
+	resp.Body = ioutil.NopCloser(bytes.NewReader(response.Body.Bytes()))
 	err = checkResponse(resp)
 	if err != nil {
 		return response, err
 	}
 
 	if v != nil {
-		if w, ok := v.(io.Writer); ok {
-			if _, err := io.Copy(w, &body); err != nil {
-				return nil, err
-			}
-		} else {
-			decErr := json.NewDecoder(&body).Decode(v)
-			if decErr == io.EOF {
-				decErr = nil // ignore EOF errors caused by empty response body
-			}
-			if decErr != nil {
-				err = decErr
-			}
+		decErr := json.NewDecoder(&body).Decode(v)
+		if decErr == io.EOF {
+			decErr = nil // ignore EOF errors caused by empty response body
+		}
+		if decErr != nil {
+			err = decErr
 		}
 	}
 
 	return response, err
 }
 
+// rewindRequest prepares the request to be sent again.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("body of the request %v %v cannot be sent again", req.Method, sanitizeURL(req.URL))
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
+
 // checkRateLimitBeforeDo does not make any network calls, but uses existing knowledge from
 // current client state in order to quickly check if *RateLimitError can be immediately returned
 // from Client.Do, and if so, returns it so that Client.Do can skip making a network API call unnecessarily.
 // Otherwise it returns nil, and Client.Do should proceed normally.
 func (c *Client) checkRateLimitBeforeDo(req *http.Request) *RateLimitError {
-	c.rateMu.Lock()
-	rate := c.rateLimit
-	c.rateMu.Unlock()
+	rate := c.rate.get()
 	if !rate.Reset.Time.IsZero() && rate.Remaining == 0 && time.Now().Before(rate.Reset.Time) {
 		// Create a fake response.
 		resp := &http.Response{
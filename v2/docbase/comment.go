@@ -85,3 +85,234 @@ func (d *commentDeleteDoer) Do(ctx context.Context) (*Response, error) {
 	}
 	return d.client.Do(ctx, req, nil)
 }
+
+// List comments of a post.
+//
+// The Docbase API has no endpoint to list comments, so List gets the post and
+// pages through its comments locally. Response.Total is set to the number of
+// the comments. Each and All get the post only once.
+func (s *commentService) List(postID PostID) *commentListDoer {
+	return &commentListDoer{client: s.client, postID: postID}
+}
+
+type commentListDoer struct {
+	client *Client
+	postID PostID
+	opts   ListOptions
+	limit  int64
+}
+
+func (d *commentListDoer) Page(page int64) *commentListDoer {
+	d.opts.Page = &page
+	return d
+}
+
+func (d *commentListDoer) PerPage(perPage int64) *commentListDoer {
+	d.opts.PerPage = &perPage
+	return d
+}
+
+// Do gets comments in the page. The page defaults to 1, and the number of
+// comments in a page defaults to 20.
+func (d *commentListDoer) Do(ctx context.Context) ([]Comment, *Response, error) {
+	comments, resp, err := d.list(ctx)
+	if err != nil {
+		return nil, resp, err
+	}
+	start, perPage, err := d.start()
+	if err != nil {
+		return nil, resp, err
+	}
+	if start >= int64(len(comments)) {
+		return []Comment{}, resp, nil
+	}
+	end := start + perPage
+	if end > int64(len(comments)) {
+		end = int64(len(comments))
+	}
+	return comments[start:end], resp, nil
+}
+
+// start gets the index of the first comment in the page, and the number of
+// comments in a page.
+func (d *commentListDoer) start() (int64, int64, error) {
+	page, perPage := int64(1), int64(20)
+	if d.opts.Page != nil {
+		page = *d.opts.Page
+	}
+	if d.opts.PerPage != nil {
+		perPage = *d.opts.PerPage
+	}
+	if page < 1 || perPage < 1 {
+		return 0, 0, fmt.Errorf("invalid page %d with %d comments per page", page, perPage)
+	}
+	return (page - 1) * perPage, perPage, nil
+}
+
+// list gets all comments of the post.
+func (d *commentListDoer) list(ctx context.Context) ([]Comment, *Response, error) {
+	post, resp, err := d.client.Post.Get(d.postID).Do(ctx)
+	if err != nil {
+		return nil, resp, err
+	}
+	resp.Total = int64(len(post.Comments))
+	return post.Comments, resp, nil
+}
+
+// Limit sets the maximum number of comments to get with Each or All.
+func (d *commentListDoer) Limit(limit int64) *commentListDoer {
+	d.limit = limit
+	return d
+}
+
+// Each calls f for each comment from the current page until all comments are
+// visited or the limit is reached. The post is got only once.
+// If f returns an error, Each stops and returns it.
+func (d *commentListDoer) Each(ctx context.Context, f func(Comment) error) error {
+	start, _, err := d.start()
+	if err != nil {
+		return err
+	}
+	comments, _, err := d.list(ctx)
+	if err != nil {
+		return err
+	}
+	if start >= int64(len(comments)) {
+		return nil
+	}
+	comments = comments[start:]
+	var count int64
+	for _, comment := range comments {
+		if limitReached(d.limit, count) {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		count++
+		if err := f(comment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// All gets all comments from the current page until all comments are got or
+// the limit is reached. The post is got only once.
+func (d *commentListDoer) All(ctx context.Context) ([]Comment, error) {
+	var comments []Comment
+	if err := d.Each(ctx, func(comment Comment) error {
+		comments = append(comments, comment)
+		return nil
+	}); err != nil {
+		return comments, err
+	}
+	return comments, nil
+}
+
+// Get a comment of a post.
+//
+// The Docbase API has no endpoint to get a comment, so Get gets the post and
+// finds the comment in it.
+// If the comment is not found, Do returns an error wrapping ErrNotFound.
+func (s *commentService) Get(postID PostID, id CommentID) *commentGetDoer {
+	return &commentGetDoer{client: s.client, postID: postID, id: id}
+}
+
+type commentGetDoer struct {
+	client *Client
+	postID PostID
+	id     CommentID
+}
+
+func (d *commentGetDoer) Do(ctx context.Context) (*Comment, *Response, error) {
+	post, resp, err := d.client.Post.Get(d.postID).Do(ctx)
+	if err != nil {
+		return nil, resp, err
+	}
+	for i := range post.Comments {
+		if post.Comments[i].ID == d.id {
+			return &post.Comments[i], resp, nil
+		}
+	}
+	return nil, resp, fmt.Errorf("%w: comment %d in the post %d", ErrNotFound, d.id, d.postID)
+}
+
+// Edit a comment of a post.
+//
+// The Docbase API has no endpoint to edit a comment, so Edit emulates it:
+// it creates a new comment with the new body and then deletes the original
+// one. So the edited comment is not the same comment:
+//
+//   - it gets a new ID, and it is placed at the end of the thread;
+//   - it is posted by the user of the access token at the time, unless
+//     Preserve(true) is set by an owner;
+//   - members are not notified, unless Notice(true) is set.
+func (s *commentService) Edit(postID PostID, id CommentID) *commentEditDoer {
+	return &commentEditDoer{client: s.client, postID: postID, id: id}
+}
+
+type commentEditDoer struct {
+	client   *Client
+	postID   PostID
+	id       CommentID
+	body     *string
+	notice   bool
+	preserve bool
+}
+
+// Body sets the new body. If it is not set, the original body is kept.
+func (d *commentEditDoer) Body(body string) *commentEditDoer {
+	d.body = &body
+	return d
+}
+
+// Notice sets whether to notify members of the new comment, as if it is
+// posted. It defaults to false.
+func (d *commentEditDoer) Notice(notice bool) *commentEditDoer {
+	d.notice = notice
+	return d
+}
+
+// Preserve sets whether to preserve the author and the published time of the
+// original comment. Only owners can preserve them. It defaults to false: the
+// edited comment is posted by the user of the access token at the time.
+// If the published time of the original comment cannot be parsed, Do returns
+// an error before creating the new comment.
+func (d *commentEditDoer) Preserve(preserve bool) *commentEditDoer {
+	d.preserve = preserve
+	return d
+}
+
+// Do edits the comment and returns the new one.
+// If the original comment cannot be deleted, Do returns the new comment with
+// the error; then both of them remain.
+func (d *commentEditDoer) Do(ctx context.Context) (*Comment, *Response, error) {
+	orig, resp, err := d.client.Comment.Get(d.postID, d.id).Do(ctx)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	body := orig.Body
+	if d.body != nil {
+		body = *d.body
+	}
+	create := d.client.Comment.Create(d.postID, body).Notice(d.notice)
+	if d.preserve {
+		publishedAt, err := time.Parse(time.RFC3339, orig.CreatedAt)
+		if err != nil {
+			return nil, resp, fmt.Errorf("cannot preserve the published time of comment %d: invalid created_at %q: %w", d.id, orig.CreatedAt, err)
+		}
+		create.AuthorID(orig.User.ID).PublishedAt(publishedAt)
+	}
+	comment, resp, err := create.Do(ctx)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	resp, err = d.client.Comment.Delete(d.id).Do(ctx)
+	if err != nil {
+		return comment, resp, fmt.Errorf("comment %d is created, but the original comment %d cannot be deleted: %w", comment.ID, d.id, err)
+	}
+	return comment, resp, nil
+}
@@ -0,0 +1,232 @@
+package docbase_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+// noticeRecorder records the notice parameter of created comments, and passes
+// the requests to the fake server.
+type noticeRecorder struct {
+	server *docbasetest.Server
+
+	mu      sync.Mutex
+	notices []*bool
+}
+
+func (h *noticeRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/comments") {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			panic(err)
+		}
+		var opts struct {
+			Notice *bool `json:"notice"`
+		}
+		_ = json.Unmarshal(body, &opts)
+		h.mu.Lock()
+		h.notices = append(h.notices, opts.Notice)
+		h.mu.Unlock()
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	h.server.ServeHTTP(w, r)
+}
+
+func (h *noticeRecorder) recorded() []*bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]*bool{}, h.notices...)
+}
+
+func TestCommentEdit(t *testing.T) {
+	const publishedAt = "2020-01-02T03:04:05Z"
+	for _, test := range []struct {
+		title      string
+		edit       func(d *docbase.CommentEditDoer)
+		wantBody   string
+		wantAuthor string
+		wantTime   bool // whether the published time is preserved
+		wantNotice bool
+	}{
+		{
+			title:      "default",
+			edit:       func(d *docbase.CommentEditDoer) { d.Body("edited") },
+			wantBody:   "edited",
+			wantAuthor: "owner",
+		},
+		{
+			title:      "body kept",
+			edit:       func(d *docbase.CommentEditDoer) {},
+			wantBody:   "original",
+			wantAuthor: "owner",
+		},
+		{
+			title:      "preserved",
+			edit:       func(d *docbase.CommentEditDoer) { d.Body("edited").Preserve(true) },
+			wantBody:   "edited",
+			wantAuthor: "alice",
+			wantTime:   true,
+		},
+		{
+			title:      "noticed",
+			edit:       func(d *docbase.CommentEditDoer) { d.Body("edited").Notice(true) },
+			wantBody:   "edited",
+			wantAuthor: "owner",
+			wantNotice: true,
+		},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			server := docbasetest.NewServer()
+			defer server.Close()
+			alice := server.AddUser(docbase.User{Name: "Alice", Username: "alice"})
+			post := server.AddPost(docbase.Post{
+				Title: "title",
+				Body:  "body",
+				Comments: []docbase.Comment{
+					{ID: 1001, Body: "original", CreatedAt: publishedAt, User: alice},
+					{ID: 1002, Body: "reply", CreatedAt: publishedAt, User: alice},
+				},
+			})
+			handler := &noticeRecorder{server: server}
+			recorder := httptest.NewServer(handler)
+			defer recorder.Close()
+			client, err := docbase.NewAuthClient(server.Domain, server.Token).WithBaseURL(recorder.URL + "/")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			doer := client.Comment.Edit(post.ID, 1001)
+			test.edit(doer)
+			comment, _, err := doer.Do(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if comment.ID == 1001 || comment.Body != test.wantBody || comment.User.Username != test.wantAuthor {
+				t.Errorf("comment = %d/%q/%q, want a new ID/%q/%q", comment.ID, comment.Body, comment.User.Username, test.wantBody, test.wantAuthor)
+			}
+			if got := comment.CreatedAt == publishedAt; got != test.wantTime {
+				t.Errorf("created at = %q, want preserved: %t", comment.CreatedAt, test.wantTime)
+			}
+			if got := handler.recorded(); len(got) != 1 || got[0] == nil || *got[0] != test.wantNotice {
+				t.Errorf("notice = %v, want %t", got, test.wantNotice)
+			}
+
+			stored, _ := server.Post(post.ID)
+			var ids []docbase.CommentID
+			for _, c := range stored.Comments {
+				ids = append(ids, c.ID)
+			}
+			if len(ids) != 2 || ids[0] != 1002 || ids[1] != comment.ID {
+				t.Errorf("comments = %v, want [1002 %d]", ids, comment.ID)
+			}
+		})
+	}
+}
+
+func TestCommentEditPreserveInvalidTime(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	post := server.AddPost(docbase.Post{
+		Title:    "title",
+		Body:     "body",
+		Comments: []docbase.Comment{{ID: 1001, Body: "original", CreatedAt: "yesterday", User: server.Owner}},
+	})
+	client := server.Client()
+
+	_, _, err := client.Comment.Edit(post.ID, 1001).Body("edited").Preserve(true).Do(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "comment 1001") || !strings.Contains(err.Error(), "created_at") {
+		t.Errorf("error = %v, want one naming the comment and created_at", err)
+	}
+	stored, _ := server.Post(post.ID)
+	if len(stored.Comments) != 1 || stored.Comments[0].Body != "original" {
+		t.Errorf("comments = %+v, want only the original", stored.Comments)
+	}
+}
+
+func TestCommentList(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	var comments []docbase.Comment
+	for i := 1; i <= 5; i++ {
+		comments = append(comments, docbase.Comment{ID: docbase.CommentID(i), Body: "comment"})
+	}
+	post := server.AddPost(docbase.Post{Title: "title", Body: "body", Comments: comments})
+	client := server.Client()
+
+	for _, test := range []struct {
+		title   string
+		page    int64
+		perPage int64
+		limit   int64
+		wantDo  []docbase.CommentID
+		wantAll []docbase.CommentID
+	}{
+		{title: "default", wantDo: []docbase.CommentID{1, 2, 3, 4, 5}, wantAll: []docbase.CommentID{1, 2, 3, 4, 5}},
+		{title: "second page", page: 2, perPage: 2, wantDo: []docbase.CommentID{3, 4}, wantAll: []docbase.CommentID{3, 4, 5}},
+		{title: "last page", page: 3, perPage: 2, wantDo: []docbase.CommentID{5}, wantAll: []docbase.CommentID{5}},
+		{title: "out of range", page: 4, perPage: 2, wantDo: []docbase.CommentID{}, wantAll: nil},
+		{title: "limited", page: 1, perPage: 2, limit: 3, wantDo: []docbase.CommentID{1, 2}, wantAll: []docbase.CommentID{1, 2, 3}},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			doer := func() *docbase.CommentListDoer {
+				d := client.Comment.List(post.ID).Limit(test.limit)
+				if test.page > 0 {
+					d.Page(test.page)
+				}
+				if test.perPage > 0 {
+					d.PerPage(test.perPage)
+				}
+				return d
+			}
+
+			got, resp, err := doer().Do(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Total != 5 {
+				t.Errorf("total = %d, want 5", resp.Total)
+			}
+			if ids := commentIDs(got); !equalCommentIDs(ids, test.wantDo) {
+				t.Errorf("Do = %v, want %v", ids, test.wantDo)
+			}
+
+			all, err := doer().All(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ids := commentIDs(all); !equalCommentIDs(ids, test.wantAll) {
+				t.Errorf("All = %v, want %v", ids, test.wantAll)
+			}
+		})
+	}
+}
+
+func commentIDs(comments []docbase.Comment) []docbase.CommentID {
+	var ids []docbase.CommentID
+	for _, c := range comments {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+func equalCommentIDs(a, b []docbase.CommentID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
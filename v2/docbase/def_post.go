@@ -9,12 +9,13 @@ type Post struct {
 	Archived      bool      `json:"archived"`
 	URL           string    `json:"url"`
 	CreatedAt     string    `json:"created_at"`
+	UpdatedAt     string    `json:"updated_at"`
 	Scope         Scope     `json:"scope"`
 	SharingURL    string    `json:"sharing_url"`
 	Tags          []Tag     `json:"tags"`
 	User          User      `json:"user"`
-	StarsCount    int64       `json:"stars_count"`
-	GoodJobsCount int64       `json:"good_jobs_count"`
+	StarsCount    int64     `json:"stars_count"`
+	GoodJobsCount int64     `json:"good_jobs_count"`
 	Comments      []Comment `json:"comments"`
 	Groups        []Group   `json:"groups"`
 }
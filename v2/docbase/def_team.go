@@ -0,0 +1,7 @@
+package docbase
+
+// Team represents a Docbase Team.
+type Team struct {
+	Domain string `json:"domain"`
+	Name   string `json:"name"`
+}
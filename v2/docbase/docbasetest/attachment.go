@@ -0,0 +1,99 @@
+package docbasetest
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+type attachment struct {
+	docbase.Attachment
+	content []byte
+}
+
+// Attachment gets an uploaded attachment and its content.
+func (s *Server) Attachment(id docbase.AttachmentID) (docbase.Attachment, []byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range s.attachments {
+		if a.ID == id {
+			return a.Attachment, append([]byte{}, a.content...), true
+		}
+	}
+	return docbase.Attachment{}, nil, false
+}
+
+func isImage(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".svg", ".bmp", ".webp":
+		return true
+	}
+	return false
+}
+
+func (s *Server) uploadAttachments(w http.ResponseWriter, r *http.Request, _ int64) {
+	var payloads []struct {
+		Name    string `json:"name"`
+		Content []byte `json:"content"`
+	}
+	if !decodeBody(w, r, &payloads) {
+		return
+	}
+	if len(payloads) == 0 {
+		writeError(w, http.StatusBadRequest, "bad_request", "No file is specified")
+		return
+	}
+	res := make([]docbase.Attachment, 0, len(payloads))
+	for _, p := range payloads {
+		if p.Name == "" {
+			writeError(w, http.StatusBadRequest, "bad_request", "Name is required")
+			return
+		}
+		id := docbase.AttachmentID(s.nextID())
+		a := docbase.Attachment{
+			ID:        id,
+			Name:      p.Name,
+			Size:      int64(len(p.Content)),
+			URL:       fmt.Sprintf("%s/uploads/%d/%s", s.URL, id, url.PathEscape(p.Name)),
+			CreatedAt: now(),
+		}
+		if isImage(p.Name) {
+			a.Markdown = fmt.Sprintf("![%s](%s)", p.Name, a.URL)
+		} else {
+			a.Markdown = fmt.Sprintf("[![%s](https://docbase.io/file_icons/%s.svg)%s](%s)", p.Name, strings.TrimPrefix(path.Ext(p.Name), "."), p.Name, a.URL)
+		}
+		s.attachments = append(s.attachments, &attachment{Attachment: a, content: p.Content})
+		res = append(res, a)
+	}
+	writeJSON(w, http.StatusCreated, res)
+}
+
+// downloadAttachment serves the content of an attachment at "uploads/<id>/<name>".
+func (s *Server) downloadAttachment(w http.ResponseWriter, _ *http.Request, elems []string) {
+	id, err := strconv.ParseInt(elems[0], 10, 64)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	for _, a := range s.attachments {
+		if a.ID != docbase.AttachmentID(id) || a.Name != elems[1] {
+			continue
+		}
+		contentType := mime.TypeByExtension(path.Ext(a.Name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(a.content)))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(a.content)
+		return
+	}
+	writeNotFound(w)
+}
@@ -0,0 +1,63 @@
+package docbasetest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+func (s *Server) createComment(w http.ResponseWriter, r *http.Request, id int64) {
+	p := s.findPost(docbase.PostID(id))
+	if p == nil {
+		writeNotFound(w)
+		return
+	}
+	var opts struct {
+		Body        string          `json:"body"`
+		Notice      *bool           `json:"notice"`
+		AuthorID    *docbase.UserID `json:"author_id"`
+		PublishedAt *time.Time      `json:"published_at"`
+	}
+	if !decodeBody(w, r, &opts) {
+		return
+	}
+	if opts.Body == "" {
+		writeError(w, http.StatusBadRequest, "bad_request", "Body is required")
+		return
+	}
+	comment := docbase.Comment{
+		ID:        docbase.CommentID(s.nextID()),
+		Body:      opts.Body,
+		CreatedAt: now().Format(time.RFC3339),
+		User:      s.Owner,
+	}
+	if opts.AuthorID != nil {
+		u := s.findUser(*opts.AuthorID)
+		if u == nil {
+			writeError(w, http.StatusBadRequest, "bad_request", fmt.Sprintf("User %d is not found", *opts.AuthorID))
+			return
+		}
+		comment.User = *u
+	}
+	comment.User.Groups = nil
+	if opts.PublishedAt != nil {
+		comment.CreatedAt = opts.PublishedAt.Format(time.RFC3339)
+	}
+	p.Comments = append(p.Comments, comment)
+	writeJSON(w, http.StatusCreated, comment)
+}
+
+func (s *Server) deleteComment(w http.ResponseWriter, _ *http.Request, id int64) {
+	for _, p := range s.posts {
+		for i, c := range p.Comments {
+			if c.ID == docbase.CommentID(id) {
+				p.Comments = append(p.Comments[:i], p.Comments[i+1:]...)
+				writeJSON(w, http.StatusNoContent, nil)
+				return
+			}
+		}
+	}
+	writeNotFound(w)
+}
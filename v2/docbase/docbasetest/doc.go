@@ -0,0 +1,10 @@
+/*
+Package docbasetest provides a fake Docbase API server for testing.
+
+	server := docbasetest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	post, _, err := client.Post.Create("title", "body").Do(ctx)
+*/
+package docbasetest
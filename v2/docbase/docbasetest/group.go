@@ -0,0 +1,194 @@
+package docbasetest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+// AddGroup stores a group in the Server and returns it.
+// If the group has no ID, a new ID is assigned.
+func (s *Server) AddGroup(group docbase.Group) docbase.Group {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.addGroup(group)
+}
+
+func (s *Server) addGroup(group docbase.Group) *docbase.Group {
+	if group.ID == 0 {
+		group.ID = docbase.GroupID(s.nextID())
+	}
+	n := now()
+	if group.CreatedAt.IsZero() {
+		group.CreatedAt = n
+	}
+	if group.LastActivityAt.IsZero() {
+		group.LastActivityAt = n
+	}
+	if group.Users == nil {
+		group.Users = []docbase.User{}
+	}
+	g := &group
+	s.groups = append(s.groups, g)
+	return g
+}
+
+// Group gets a group stored in the Server.
+func (s *Server) Group(id docbase.GroupID) (docbase.Group, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g := s.findGroup(id)
+	if g == nil {
+		return docbase.Group{}, false
+	}
+	return *g, true
+}
+
+func (s *Server) findGroup(id docbase.GroupID) *docbase.Group {
+	for _, g := range s.groups {
+		if g.ID == id {
+			return g
+		}
+	}
+	return nil
+}
+
+// summarizeGroup strips members from the group as the list endpoints do.
+func summarizeGroup(g *docbase.Group) docbase.Group {
+	summary := *g
+	summary.Users = nil
+	return summary
+}
+
+func (s *Server) listGroups(w http.ResponseWriter, r *http.Request, _ int64) {
+	page, perPage, ok := paging(w, r)
+	if !ok {
+		return
+	}
+	name := r.URL.Query().Get("name")
+	var matched []docbase.Group
+	for _, g := range s.groups {
+		if name != "" && g.Name != name {
+			continue
+		}
+		matched = append(matched, summarizeGroup(g))
+	}
+	start, end := pageRange(len(matched), page, perPage)
+	writeJSON(w, http.StatusOK, append([]docbase.Group{}, matched[start:end]...))
+}
+
+func (s *Server) createGroup(w http.ResponseWriter, r *http.Request, _ int64) {
+	var opts struct {
+		Name        string  `json:"name"`
+		Description *string `json:"description"`
+	}
+	if !decodeBody(w, r, &opts) {
+		return
+	}
+	if opts.Name == "" {
+		writeError(w, http.StatusBadRequest, "bad_request", "Name is required")
+		return
+	}
+	for _, g := range s.groups {
+		if g.Name == opts.Name {
+			writeError(w, http.StatusBadRequest, "bad_request", fmt.Sprintf("Name %q has already been taken", opts.Name))
+			return
+		}
+	}
+	group := docbase.Group{Name: opts.Name}
+	if opts.Description != nil {
+		group.Description = *opts.Description
+	}
+	writeJSON(w, http.StatusCreated, s.addGroup(group))
+}
+
+func (s *Server) getGroup(w http.ResponseWriter, _ *http.Request, id int64) {
+	g := s.findGroup(docbase.GroupID(id))
+	if g == nil {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, g)
+}
+
+// groupUsers reads "user_ids" in the request body and finds the users.
+func (s *Server) groupUsers(w http.ResponseWriter, r *http.Request) ([]*docbase.User, bool) {
+	var opts struct {
+		UserIDs []docbase.UserID `json:"user_ids"`
+	}
+	if !decodeBody(w, r, &opts) {
+		return nil, false
+	}
+	users := make([]*docbase.User, 0, len(opts.UserIDs))
+	for _, id := range opts.UserIDs {
+		u := s.findUser(id)
+		if u == nil {
+			writeError(w, http.StatusBadRequest, "bad_request", fmt.Sprintf("User %d is not found", id))
+			return nil, false
+		}
+		users = append(users, u)
+	}
+	return users, true
+}
+
+func (s *Server) addGroupUsers(w http.ResponseWriter, r *http.Request, id int64) {
+	g := s.findGroup(docbase.GroupID(id))
+	if g == nil {
+		writeNotFound(w)
+		return
+	}
+	users, ok := s.groupUsers(w, r)
+	if !ok {
+		return
+	}
+	for _, u := range users {
+		s.join(g, u)
+	}
+	writeJSON(w, http.StatusOK, nil)
+}
+
+func (s *Server) removeGroupUsers(w http.ResponseWriter, r *http.Request, id int64) {
+	g := s.findGroup(docbase.GroupID(id))
+	if g == nil {
+		writeNotFound(w)
+		return
+	}
+	users, ok := s.groupUsers(w, r)
+	if !ok {
+		return
+	}
+	for _, u := range users {
+		s.leave(g, u)
+	}
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// join makes the user a member of the group.
+func (s *Server) join(g *docbase.Group, u *docbase.User) {
+	for _, m := range g.Users {
+		if m.ID == u.ID {
+			return
+		}
+	}
+	member := *u
+	member.Groups = nil
+	g.Users = append(g.Users, member)
+	u.Groups = append(u.Groups, docbase.Group{ID: g.ID, Name: g.Name})
+}
+
+// leave removes the user from members of the group.
+func (s *Server) leave(g *docbase.Group, u *docbase.User) {
+	for i, m := range g.Users {
+		if m.ID == u.ID {
+			g.Users = append(g.Users[:i], g.Users[i+1:]...)
+			break
+		}
+	}
+	for i, ug := range u.Groups {
+		if ug.ID == g.ID {
+			u.Groups = append(u.Groups[:i], u.Groups[i+1:]...)
+			break
+		}
+	}
+}
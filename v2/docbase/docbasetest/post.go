@@ -0,0 +1,299 @@
+package docbasetest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/postquery"
+)
+
+// AddPost stores a post in the Server and returns it.
+// If the post has no ID, a new ID is assigned.
+// If the post has no author, the Owner is set.
+func (s *Server) AddPost(post docbase.Post) docbase.Post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.addPost(post)
+}
+
+func (s *Server) addPost(post docbase.Post) *docbase.Post {
+	if post.ID == 0 {
+		post.ID = docbase.PostID(s.nextID())
+	}
+	if post.User.ID == 0 {
+		post.User = s.Owner
+	}
+	post.User.Groups = nil
+	if post.CreatedAt == "" {
+		post.CreatedAt = now().Format(time.RFC3339)
+	}
+	if post.UpdatedAt == "" {
+		post.UpdatedAt = post.CreatedAt
+	}
+	if post.Scope == "" {
+		post.Scope = docbase.ScopeEveryone
+	}
+	if post.URL == "" {
+		post.URL = fmt.Sprintf("https://%s.docbase.io/posts/%d", s.Domain, post.ID)
+	}
+	if post.Tags == nil {
+		post.Tags = []docbase.Tag{}
+	}
+	if post.Groups == nil {
+		post.Groups = []docbase.Group{}
+	}
+	if post.Comments == nil {
+		post.Comments = []docbase.Comment{}
+	}
+	for _, tag := range post.Tags {
+		s.addTag(tag.Name)
+	}
+	p := &post
+	s.posts = append(s.posts, p)
+	return p
+}
+
+// Post gets a post stored in the Server.
+func (s *Server) Post(id docbase.PostID) (docbase.Post, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.findPost(id)
+	if p == nil {
+		return docbase.Post{}, false
+	}
+	return *p, true
+}
+
+func (s *Server) findPost(id docbase.PostID) *docbase.Post {
+	for _, p := range s.posts {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *Server) listPosts(w http.ResponseWriter, r *http.Request, _ int64) {
+	page, perPage, ok := paging(w, r)
+	if !ok {
+		return
+	}
+
+	query, err := postquery.Parse(r.URL.Query().Get("q"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", fmt.Sprintf("Invalid query: %s", err))
+		return
+	}
+	query = evaluable(query)
+	var matched []docbase.Post
+	// Newer posts come first.
+	for i := len(s.posts) - 1; i >= 0; i-- {
+		p := s.posts[i]
+		if p.Archived {
+			continue
+		}
+		ok, err := query.Match(p)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "bad_request", fmt.Sprintf("Invalid query: %s", err))
+			return
+		}
+		if ok {
+			matched = append(matched, *p)
+		}
+	}
+
+	start, end := pageRange(len(matched), page, perPage)
+	var res struct {
+		Posts []docbase.Post `json:"posts"`
+		Meta  struct {
+			PreviousPage *string `json:"previous_page"`
+			NextPage     *string `json:"next_page"`
+			Total        int     `json:"total"`
+		} `json:"meta"`
+	}
+	res.Posts = append([]docbase.Post{}, matched[start:end]...)
+	res.Meta.Total = len(matched)
+	if page > 1 {
+		res.Meta.PreviousPage = s.pageURL(r, page-1, perPage)
+	}
+	if end < len(matched) {
+		res.Meta.NextPage = s.pageURL(r, page+1, perPage)
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// evaluable drops clauses of the query which have terms that cannot be
+// evaluated locally (e.g. "has:star", "liked_by:"), so that they match any
+// posts instead of rejecting valid queries.
+func evaluable(query *postquery.Query) *postquery.Query {
+	sample := &docbase.Post{CreatedAt: now().Format(time.RFC3339)}
+	evaluated := &postquery.Query{Sort: query.Sort}
+	for _, clause := range query.Clauses {
+		ok := true
+		for _, term := range clause {
+			if _, err := postquery.New(term).Match(sample); errors.Is(err, postquery.ErrUnsupported) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			evaluated.Clauses = append(evaluated.Clauses, clause)
+		}
+	}
+	return evaluated
+}
+
+func (s *Server) getPost(w http.ResponseWriter, _ *http.Request, id int64) {
+	p := s.findPost(docbase.PostID(id))
+	if p == nil {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+type postOptions struct {
+	Title       *string            `json:"title"`
+	Body        *string            `json:"body"`
+	Draft       *bool              `json:"draft"`
+	Groups      *[]docbase.GroupID `json:"groups"`
+	Scope       *docbase.Scope     `json:"scope"`
+	Tags        *[]string          `json:"tags"`
+	Notice      *bool              `json:"notice"`
+	AuthorID    *docbase.UserID    `json:"author_id"`
+	PublishedAt *time.Time         `json:"published_at"`
+}
+
+// applyPostOptions sets the options to the post, or writes an error and returns false.
+func (s *Server) applyPostOptions(w http.ResponseWriter, post *docbase.Post, opts postOptions) bool {
+	if opts.Title != nil {
+		post.Title = *opts.Title
+	}
+	if opts.Body != nil {
+		post.Body = *opts.Body
+	}
+	if post.Title == "" || post.Body == "" {
+		writeError(w, http.StatusBadRequest, "bad_request", "Title and body are required")
+		return false
+	}
+	if opts.Draft != nil {
+		post.Draft = *opts.Draft
+	}
+	if opts.Scope != nil {
+		switch *opts.Scope {
+		case docbase.ScopeEveryone, docbase.ScopeGroup, docbase.ScopePrivate:
+			post.Scope = *opts.Scope
+		default:
+			writeError(w, http.StatusBadRequest, "bad_request", fmt.Sprintf("Scope %q is invalid", *opts.Scope))
+			return false
+		}
+	}
+	if opts.Groups != nil {
+		groups := []docbase.Group{}
+		for _, id := range *opts.Groups {
+			g := s.findGroup(id)
+			if g == nil {
+				writeError(w, http.StatusBadRequest, "bad_request", fmt.Sprintf("Group %d is not found", id))
+				return false
+			}
+			groups = append(groups, docbase.Group{ID: g.ID, Name: g.Name})
+		}
+		post.Groups = groups
+	}
+	if post.Scope == docbase.ScopeGroup && len(post.Groups) == 0 {
+		writeError(w, http.StatusBadRequest, "bad_request", "Groups are required for the group scope")
+		return false
+	}
+	if post.Scope != docbase.ScopeGroup {
+		post.Groups = []docbase.Group{}
+	}
+	if opts.Tags != nil {
+		tags := []docbase.Tag{}
+		for _, name := range *opts.Tags {
+			tags = append(tags, docbase.Tag{Name: name})
+		}
+		post.Tags = tags
+	}
+	if opts.AuthorID != nil {
+		u := s.findUser(*opts.AuthorID)
+		if u == nil {
+			writeError(w, http.StatusBadRequest, "bad_request", fmt.Sprintf("User %d is not found", *opts.AuthorID))
+			return false
+		}
+		post.User = *u
+		post.User.Groups = nil
+	}
+	if opts.PublishedAt != nil {
+		post.CreatedAt = opts.PublishedAt.Format(time.RFC3339)
+	}
+	return true
+}
+
+func (s *Server) createPost(w http.ResponseWriter, r *http.Request, _ int64) {
+	var opts postOptions
+	if !decodeBody(w, r, &opts) {
+		return
+	}
+	post := docbase.Post{Scope: docbase.ScopeEveryone}
+	if !s.applyPostOptions(w, &post, opts) {
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.addPost(post))
+}
+
+func (s *Server) editPost(w http.ResponseWriter, r *http.Request, id int64) {
+	p := s.findPost(docbase.PostID(id))
+	if p == nil {
+		writeNotFound(w)
+		return
+	}
+	var opts postOptions
+	if !decodeBody(w, r, &opts) {
+		return
+	}
+	// Owner only parameters are not accepted in editing.
+	opts.AuthorID = nil
+	opts.PublishedAt = nil
+	post := *p
+	if !s.applyPostOptions(w, &post, opts) {
+		return
+	}
+	for _, tag := range post.Tags {
+		s.addTag(tag.Name)
+	}
+	post.UpdatedAt = now().Format(time.RFC3339)
+	*p = post
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) deletePost(w http.ResponseWriter, _ *http.Request, id int64) {
+	for i, p := range s.posts {
+		if p.ID == docbase.PostID(id) {
+			s.posts = append(s.posts[:i], s.posts[i+1:]...)
+			writeJSON(w, http.StatusNoContent, nil)
+			return
+		}
+	}
+	writeNotFound(w)
+}
+
+func (s *Server) archivePost(w http.ResponseWriter, _ *http.Request, id int64) {
+	s.setArchived(w, docbase.PostID(id), true)
+}
+
+func (s *Server) unarchivePost(w http.ResponseWriter, _ *http.Request, id int64) {
+	s.setArchived(w, docbase.PostID(id), false)
+}
+
+func (s *Server) setArchived(w http.ResponseWriter, id docbase.PostID, archived bool) {
+	p := s.findPost(id)
+	if p == nil {
+		writeNotFound(w)
+		return
+	}
+	p.Archived = archived
+	writeJSON(w, http.StatusNoContent, nil)
+}
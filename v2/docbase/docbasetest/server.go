@@ -0,0 +1,314 @@
+package docbasetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+const (
+	// DefaultDomain is the team domain which a Server created by NewServer serves.
+	DefaultDomain = "example"
+	// DefaultToken is the access token which a Server created by NewServer accepts.
+	DefaultToken = "docbasetest-token"
+	// DefaultRateLimit is the number of requests a Server accepts in a RateWindow.
+	DefaultRateLimit = 300
+	// DefaultRateWindow is the span in which the rate limit will be reset.
+	DefaultRateWindow = 5 * time.Minute
+
+	defaultPerPage = 20
+	maxPerPage     = 100
+
+	headerToken         = "X-DocBaseToken"
+	headerRateLimit     = "X-RateLimit-Limit"
+	headerRateRemaining = "X-RateLimit-Remaining"
+	headerRateReset     = "X-RateLimit-Reset"
+)
+
+// Server is a fake of the Docbase API which holds every resources in memory.
+// It serves posts, comments, groups, users, tags and attachments endpoints
+// for a single team with a single access token, and lists the team in the
+// teams endpoint. Uploaded attachments can be downloaded from their URLs.
+//
+// Posts are searched with postquery.Query.Match. Terms which cannot be
+// evaluated locally (e.g. "has:star", "is:unread", "liked_by:") are ignored,
+// together with terms joined to them with OR.
+type Server struct {
+	*httptest.Server
+
+	// Domain is the team domain served by the Server.
+	Domain string
+	// Token is the access token which the Server accepts.
+	Token string
+	// Owner is the user who owns the Token.
+	// Posts and comments without "author_id" are created by the user.
+	Owner docbase.User
+
+	mu sync.Mutex
+
+	rateLimit     int64
+	rateRemaining int64
+	rateReset     time.Time
+	rateWindow    time.Duration
+
+	lastID      int64
+	users       []*docbase.User
+	groups      []*docbase.Group
+	posts       []*docbase.Post
+	tags        []docbase.Tag
+	attachments []*attachment
+}
+
+// NewServer starts and returns a new Server.
+// The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		Domain:     DefaultDomain,
+		Token:      DefaultToken,
+		rateLimit:  DefaultRateLimit,
+		rateWindow: DefaultRateWindow,
+	}
+	s.rateRemaining = s.rateLimit
+	s.Owner = s.AddUser(docbase.User{
+		Name:     "Owner",
+		Username: "owner",
+		Role:     docbase.UserRoleOwner,
+	})
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Client returns a new *docbase.Client which is authenticated with the Token
+// and sends requests to the Server.
+func (s *Server) Client() *docbase.Client {
+	client, err := docbase.NewAuthClient(s.Domain, s.Token).WithBaseURL(s.URL + "/")
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// SetRate overwrites the current rate limit state of the Server.
+func (s *Server) SetRate(remaining int64, reset time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateRemaining = remaining
+	// The reset time is responded in seconds.
+	s.rateReset = reset.Truncate(time.Second)
+}
+
+func (s *Server) nextID() int64 {
+	s.lastID++
+	return s.lastID
+}
+
+func now() time.Time {
+	return time.Now().Truncate(time.Second)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get(headerToken) != s.Token {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Token is invalid")
+		return
+	}
+
+	if !s.consumeRate(w) {
+		writeError(w, http.StatusTooManyRequests, "too_many_requests", "Rate limit exceeded")
+		return
+	}
+
+	elems := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(elems) == 3 && elems[0] == "uploads" && r.Method == http.MethodGet {
+		s.downloadAttachment(w, r, elems[1:])
+		return
+	}
+	if len(elems) == 1 && elems[0] == "teams" && r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, []docbase.Team{{Domain: s.Domain, Name: s.Domain}})
+		return
+	}
+	if len(elems) < 3 || elems[0] != "teams" || elems[1] != s.Domain {
+		writeNotFound(w)
+		return
+	}
+	s.route(w, r, elems[2:])
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request, elems []string) {
+	type route struct {
+		method  string
+		pattern string
+		handler func(w http.ResponseWriter, r *http.Request, id int64)
+	}
+	routes := []route{
+		{http.MethodGet, "posts", s.listPosts},
+		{http.MethodPost, "posts", s.createPost},
+		{http.MethodGet, "posts/:id", s.getPost},
+		{http.MethodPatch, "posts/:id", s.editPost},
+		{http.MethodDelete, "posts/:id", s.deletePost},
+		{http.MethodPut, "posts/:id/archive", s.archivePost},
+		{http.MethodPut, "posts/:id/unarchive", s.unarchivePost},
+		{http.MethodPost, "posts/:id/comments", s.createComment},
+		{http.MethodDelete, "comments/:id", s.deleteComment},
+		{http.MethodGet, "groups", s.listGroups},
+		{http.MethodPost, "groups", s.createGroup},
+		{http.MethodGet, "groups/:id", s.getGroup},
+		{http.MethodPost, "groups/:id/users", s.addGroupUsers},
+		{http.MethodDelete, "groups/:id/users", s.removeGroupUsers},
+		{http.MethodGet, "users", s.listUsers},
+		{http.MethodGet, "tags", s.listTags},
+		{http.MethodPost, "attachments", s.uploadAttachments},
+	}
+
+	allowed := false
+	for _, rt := range routes {
+		id, ok := matchPattern(rt.pattern, elems)
+		if !ok {
+			continue
+		}
+		if rt.method != r.Method {
+			allowed = true
+			continue
+		}
+		rt.handler(w, r, id)
+		return
+	}
+	if allowed {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+	writeNotFound(w)
+}
+
+// matchPattern matches path elements with the pattern like "posts/:id/comments".
+func matchPattern(pattern string, elems []string) (int64, bool) {
+	parts := strings.Split(pattern, "/")
+	if len(parts) != len(elems) {
+		return 0, false
+	}
+	var id int64
+	for i, part := range parts {
+		if part == ":id" {
+			v, err := strconv.ParseInt(elems[i], 10, 64)
+			if err != nil {
+				return 0, false
+			}
+			id = v
+			continue
+		}
+		if part != elems[i] {
+			return 0, false
+		}
+	}
+	return id, true
+}
+
+// consumeRate consumes a request from the rate limit and writes its headers.
+// It returns false if the rate limit is exceeded.
+func (s *Server) consumeRate(w http.ResponseWriter) bool {
+	if n := now(); !n.Before(s.rateReset) {
+		s.rateRemaining = s.rateLimit
+		s.rateReset = n.Add(s.rateWindow)
+	}
+	ok := s.rateRemaining > 0
+	if ok {
+		s.rateRemaining--
+	}
+	w.Header().Set(headerRateLimit, strconv.FormatInt(s.rateLimit, 10))
+	w.Header().Set(headerRateRemaining, strconv.FormatInt(s.rateRemaining, 10))
+	w.Header().Set(headerRateReset, strconv.FormatInt(s.rateReset.Unix(), 10))
+	return ok
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if v == nil {
+		return
+	}
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, value string, messages ...string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error":    value,
+		"messages": messages,
+	})
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, "not_found", "Not found")
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", fmt.Sprintf("Invalid JSON: %s", err))
+		return false
+	}
+	return true
+}
+
+// paging parses "page" and "per_page" query parameters.
+func paging(w http.ResponseWriter, r *http.Request) (page, perPage int, ok bool) {
+	page, perPage = 1, defaultPerPage
+	q := r.URL.Query()
+	if v := q.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "bad_request", "page is invalid")
+			return 0, 0, false
+		}
+		page = n
+	}
+	if v := q.Get("per_page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "bad_request", "per_page is invalid")
+			return 0, 0, false
+		}
+		if n > maxPerPage {
+			n = maxPerPage
+		}
+		perPage = n
+	}
+	return page, perPage, true
+}
+
+// pageRange gets a range of the page in the total items.
+func pageRange(total, page, perPage int) (start, end int) {
+	start = (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+	end = start + perPage
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+// pageURL builds an URL to get another page of the request.
+func (s *Server) pageURL(r *http.Request, page, perPage int) *string {
+	q := r.URL.Query()
+	q.Set("page", strconv.Itoa(page))
+	q.Set("per_page", strconv.Itoa(perPage))
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return nil
+	}
+	u.Path = r.URL.Path
+	u.RawQuery = q.Encode()
+	str := u.String()
+	return &str
+}
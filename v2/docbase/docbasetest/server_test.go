@@ -0,0 +1,163 @@
+package docbasetest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+func TestServerPosts(t *testing.T) {
+	ctx := context.Background()
+	server := docbasetest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	created, _, err := client.Post.Create("title", "body").Tags([]string{"go"}).Do(ctx)
+	if err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if created.User.ID != server.Owner.ID {
+		t.Errorf("author = %d, want the owner %d", created.User.ID, server.Owner.ID)
+	}
+
+	edited, _, err := client.Post.Edit(created.ID).Title("new title").Do(ctx)
+	if err != nil {
+		t.Fatalf("edit: %s", err)
+	}
+	if edited.Title != "new title" || edited.Body != "body" {
+		t.Errorf("edited = %q/%q, want %q/%q", edited.Title, edited.Body, "new title", "body")
+	}
+
+	got, _, err := client.Post.Get(created.ID).Do(ctx)
+	if err != nil {
+		t.Fatalf("get: %s", err)
+	}
+	if got.Title != "new title" || len(got.Tags) != 1 || got.Tags[0].Name != "go" {
+		t.Errorf("got %+v", got)
+	}
+
+	tags, _, err := client.Tag.List().Do(ctx)
+	if err != nil {
+		t.Fatalf("list tags: %s", err)
+	}
+	if len(tags) != 1 || tags[0].Name != "go" {
+		t.Errorf("tags = %+v, want [go]", tags)
+	}
+
+	if _, err := client.Post.Delete(created.ID).Do(ctx); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+	if _, _, err := client.Post.Get(created.ID).Do(ctx); !errors.Is(err, docbase.ErrNotFound) {
+		t.Errorf("get after delete: error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestServerListPosts(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	server.AddPost(docbase.Post{Title: "first", Body: "b", Tags: []docbase.Tag{{Name: "go"}}})
+	server.AddPost(docbase.Post{Title: "second", Body: "b", Draft: true})
+	server.AddPost(docbase.Post{Title: "third", Body: "b", Archived: true})
+
+	for _, test := range []struct {
+		title string
+		query string
+		want  []string
+	}{
+		{title: "all", query: "", want: []string{"second", "first"}},
+		{title: "tag", query: "tag:go", want: []string{"first"}},
+		{title: "draft", query: "is:draft", want: []string{"second"}},
+		{title: "keyword", query: "third", want: nil},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			list := server.Client().Post.List()
+			if test.query != "" {
+				list.Query(test.query)
+			}
+			posts, resp, err := list.Do(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var titles []string
+			for _, post := range posts {
+				titles = append(titles, post.Title)
+			}
+			if !equalStrings(titles, test.want) {
+				t.Errorf("titles = %q, want %q", titles, test.want)
+			}
+			if resp.Total != int64(len(test.want)) {
+				t.Errorf("total = %d, want %d", resp.Total, len(test.want))
+			}
+		})
+	}
+}
+
+func TestServerAuthAndRate(t *testing.T) {
+	ctx := context.Background()
+	server := docbasetest.NewServer()
+	defer server.Close()
+
+	unauthorized, err := docbase.NewAuthClient(server.Domain, "wrong").WithBaseURL(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := unauthorized.Tag.List().Do(ctx); !errors.Is(err, docbase.ErrUnauthorized) {
+		t.Errorf("error = %v, want ErrUnauthorized", err)
+	}
+
+	client := server.Client()
+	_, resp, err := client.Tag.List().Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Rate.Limit != docbasetest.DefaultRateLimit {
+		t.Errorf("rate limit = %d, want %d", resp.Rate.Limit, docbasetest.DefaultRateLimit)
+	}
+
+	server.SetRate(0, time.Now().Add(time.Hour))
+	if _, _, err := client.Tag.List().Do(ctx); !errors.Is(err, docbase.ErrRateLimited) {
+		t.Errorf("error = %v, want ErrRateLimited", err)
+	}
+}
+
+func TestServerGroups(t *testing.T) {
+	ctx := context.Background()
+	server := docbasetest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	alice := server.AddUser(docbase.User{Username: "alice", Name: "Alice"})
+
+	group, _, err := client.Group.Create("dev").Description("developers").Do(ctx)
+	if err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if _, err := client.Group.AddUsers(group.ID, []docbase.UserID{alice.ID}).Do(ctx); err != nil {
+		t.Fatalf("add users: %s", err)
+	}
+	got, _, err := client.Group.Get(group.ID).Do(ctx)
+	if err != nil {
+		t.Fatalf("get: %s", err)
+	}
+	if len(got.Users) != 1 || got.Users[0].ID != alice.ID {
+		t.Errorf("users = %+v, want [alice]", got.Users)
+	}
+	if _, _, err := client.Group.Create("dev").Do(ctx); !errors.Is(err, docbase.ErrValidation) {
+		t.Errorf("duplicated group: error = %v, want ErrValidation", err)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
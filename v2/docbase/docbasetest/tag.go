@@ -0,0 +1,20 @@
+package docbasetest
+
+import (
+	"net/http"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+func (s *Server) addTag(name string) {
+	for _, t := range s.tags {
+		if t.Name == name {
+			return
+		}
+	}
+	s.tags = append(s.tags, docbase.Tag{Name: name})
+}
+
+func (s *Server) listTags(w http.ResponseWriter, _ *http.Request, _ int64) {
+	writeJSON(w, http.StatusOK, append([]docbase.Tag{}, s.tags...))
+}
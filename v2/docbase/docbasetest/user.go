@@ -0,0 +1,75 @@
+package docbasetest
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+// AddUser stores a user in the Server and returns it.
+// If the user has no ID, a new ID is assigned.
+// Groups of the user are ignored; use AddGroupUsers to make it a member.
+func (s *Server) AddUser(user docbase.User) docbase.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if user.ID == 0 {
+		user.ID = docbase.UserID(s.nextID())
+	}
+	user.Groups = nil
+	u := &user
+	s.users = append(s.users, u)
+	return *u
+}
+
+// AddGroupUsers makes the users members of the group.
+func (s *Server) AddGroupUsers(groupID docbase.GroupID, userIDs ...docbase.UserID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g := s.findGroup(groupID)
+	if g == nil {
+		return
+	}
+	for _, id := range userIDs {
+		if u := s.findUser(id); u != nil {
+			s.join(g, u)
+		}
+	}
+}
+
+func (s *Server) findUser(id docbase.UserID) *docbase.User {
+	for _, u := range s.users {
+		if u.ID == id {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request, _ int64) {
+	page, perPage, ok := paging(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	keyword := strings.ToLower(q.Get("q"))
+	includeGroups := q.Get("include_user_groups") == "true"
+
+	var matched []docbase.User
+	for _, u := range s.users {
+		if keyword != "" &&
+			!strings.Contains(strings.ToLower(u.Name), keyword) &&
+			!strings.Contains(strings.ToLower(u.Username), keyword) {
+			continue
+		}
+		user := *u
+		if includeGroups {
+			user.Groups = append([]docbase.Group{}, u.Groups...)
+		} else {
+			user.Groups = nil
+		}
+		matched = append(matched, user)
+	}
+	start, end := pageRange(len(matched), page, perPage)
+	writeJSON(w, http.StatusOK, append([]docbase.User{}, matched[start:end]...))
+}
@@ -3,13 +3,51 @@ package docbase
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
+// Errors to classify API errors with errors.Is.
+// *ErrorResponse and *RateLimitError wrap one of them by the status code.
+var (
+	// ErrNotFound is for 404 Not Found: the resource does not exist, or the
+	// user of the access token cannot see it.
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized is for 401 Unauthorized: the access token is invalid.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is for 403 Forbidden: the user of the access token is not
+	// permitted to do it.
+	ErrForbidden = errors.New("forbidden")
+	// ErrValidation is for 400 Bad Request and 422 Unprocessable Entity: the
+	// parameters are invalid. See Messages of the ErrorResponse for details.
+	ErrValidation = errors.New("validation failed")
+	// ErrRateLimited is for 429 Too Many Requests: the rate limit is exceeded.
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// classifyStatus gets the error to classify the status code, or nil.
+func classifyStatus(code int) error {
+	switch code {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrValidation
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	}
+	return nil
+}
+
 /*
 An ErrorResponse reports one or more errors caused by an API request.
 
@@ -19,26 +57,76 @@ type ErrorResponse struct {
 	Response *http.Response // HTTP response that caused this error
 	Messages []string       `json:"messages"` // more detail on individual errors
 	Value    string         `json:"error"`    // error message
+
+	// ContentType is the content type of the response body.
+	ContentType string `json:"-"`
+	// Body is the beginning of the response body, kept when the body is not
+	// an error in JSON (e.g. an HTML page from a proxy).
+	Body string `json:"-"`
 }
 
 func (r *ErrorResponse) Error() string {
+	if r.Value == "" && len(r.Messages) == 0 {
+		msg := fmt.Sprintf("%v %v: %d %s",
+			r.Response.Request.Method, sanitizeURL(r.Response.Request.URL),
+			r.Response.StatusCode, http.StatusText(r.Response.StatusCode))
+		if r.Body != "" {
+			msg += fmt.Sprintf(" (%s): %s", r.ContentType, strings.Join(strings.Fields(r.Body), " "))
+		}
+		return msg
+	}
 	return fmt.Sprintf("%v %v: %d %+v %v",
 		r.Response.Request.Method, sanitizeURL(r.Response.Request.URL),
 		r.Response.StatusCode, r.Value, r.Messages)
 }
 
+// Retryable reports whether the request may succeed if it is sent again
+// later: the status code is one of DefaultRetryableStatuses, which Client.Do
+// retries with a RetryPolicy by default. It does not consider whether the
+// request is idempotent.
+func (r *ErrorResponse) Retryable() bool {
+	return containsStatus(DefaultRetryableStatuses, r.Response.StatusCode)
+}
+
+// Unwrap gets the error which classifies the response (e.g. ErrNotFound), to
+// check it with errors.Is. It is nil if the status code is not classified.
+func (r *ErrorResponse) Unwrap() error {
+	return classifyStatus(r.Response.StatusCode)
+}
+
 // RateLimitError occurs when Docbase returns 429 Too many requests response
 // with a rate limit remaining value of 0.
 type RateLimitError struct {
 	Rate     Rate           // Rate specifies last known rate limit for the client
 	Response *http.Response // HTTP response that caused this error
 	Messages []string       `json:"messages"` // error message
+
+	// ContentType is the content type of the response body.
+	ContentType string `json:"-"`
+	// Body is the beginning of the response body, kept when the body is not
+	// an error in JSON (e.g. an HTML page from a proxy).
+	Body string `json:"-"`
 }
 
 func (r *RateLimitError) Error() string {
-	return fmt.Sprintf("%v %v: %d %+v %v",
+	msg := fmt.Sprintf("%v %v: %d %+v %v",
 		r.Response.Request.Method, sanitizeURL(r.Response.Request.URL),
 		r.Response.StatusCode, r.Messages, formatRateReset(time.Until(r.Rate.Reset.Time)))
+	if r.Body != "" {
+		msg += fmt.Sprintf(" (%s): %s", r.ContentType, strings.Join(strings.Fields(r.Body), " "))
+	}
+	return msg
+}
+
+// Unwrap gets ErrRateLimited, to check it with errors.Is.
+func (r *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// Retryable reports true, since the request may succeed after the rate limit
+// is reset.
+func (r *RateLimitError) Retryable() bool {
+	return true
 }
 
 // sanitizeURL redacts the client_secret parameter from the URL which may be
@@ -85,36 +173,58 @@ func (e *Error) Error() string {
 		e.Code, e.Field, e.Resource)
 }
 
+// maxErrorBodySnippet is the maximum length of ErrorResponse.Body.
+const maxErrorBodySnippet = 512
+
 // checkResponse checks the API response for errors, and returns them if
 // present. A response is considered an error if it has a status code outside
 // the 200 range.
 // API error responses are expected to have either no response
 // body, or a JSON response body that maps to ErrorResponse. Any other
-// response body will be silently ignored.
+// response body is kept in ErrorResponse.Body, cut to a snippet.
 //
 // The error type will be *RateLimitError for rate limit exceeded errors.
 func checkResponse(r *http.Response) error {
 	if c := r.StatusCode; 200 <= c && c <= 299 {
 		return nil
 	}
-	errorResponse := &ErrorResponse{Response: r}
+	errorResponse := &ErrorResponse{Response: r, ContentType: r.Header.Get("Content-Type")}
 	data, err := ioutil.ReadAll(r.Body)
-	if err == nil && data != nil {
+	if err == nil && len(data) > 0 {
 		if err := json.Unmarshal(data, errorResponse); err != nil {
-			return err
+			errorResponse.Messages, errorResponse.Value = nil, ""
+		}
+		if errorResponse.Value == "" && len(errorResponse.Messages) == 0 {
+			errorResponse.Body = snippet(data, maxErrorBodySnippet)
 		}
 	}
 	switch {
 	case r.StatusCode == http.StatusTooManyRequests:
 		return &RateLimitError{
-			Rate:     parseRate(r),
-			Response: errorResponse.Response,
-			Messages: errorResponse.Messages,
+			Rate:        parseRate(r),
+			Response:    errorResponse.Response,
+			Messages:    errorResponse.Messages,
+			ContentType: errorResponse.ContentType,
+			Body:        errorResponse.Body,
 		}
 	default:
 		return errorResponse
 	}
 }
+
+// snippet cuts the data to at most max bytes, without breaking a UTF-8
+// character.
+func snippet(data []byte, max int) string {
+	if len(data) <= max {
+		return strings.TrimSpace(string(data))
+	}
+	cut := max
+	for cut > max-utf8.UTFMax && cut > 0 && !utf8.RuneStart(data[cut]) {
+		cut--
+	}
+	return strings.TrimSpace(string(data[:cut])) + "..."
+}
+
 func sanitizeError(ctx context.Context, err error) error {
 	// If we got an error, and the context has been canceled,
 	// the context's error is probably more useful.
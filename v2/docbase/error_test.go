@@ -0,0 +1,156 @@
+package docbase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+func TestErrorResponse(t *testing.T) {
+	page := "<html><body>" + strings.Repeat("Bad Gateway ", 100) + "</body></html>"
+	for _, test := range []struct {
+		title       string
+		status      int
+		contentType string
+		body        string
+
+		wantRetryable bool
+		wantIs        error
+		wantMessages  []string
+		wantBody      string
+	}{
+		{
+			title:         "html page",
+			status:        http.StatusBadGateway,
+			contentType:   "text/html",
+			body:          page,
+			wantRetryable: true,
+			wantBody:      page[:docbase.MaxErrorBodySnippet] + "...",
+		},
+		{
+			title:        "json error",
+			status:       http.StatusNotFound,
+			contentType:  "application/json",
+			body:         `{"error":"not_found","messages":["Not found."]}`,
+			wantIs:       docbase.ErrNotFound,
+			wantMessages: []string{"Not found."},
+		},
+		{
+			title:         "unavailable",
+			status:        http.StatusServiceUnavailable,
+			contentType:   "text/plain",
+			body:          "maintenance\n",
+			wantRetryable: true,
+			wantBody:      "maintenance",
+		},
+		{
+			title:       "internal error",
+			status:      http.StatusInternalServerError,
+			contentType: "text/plain",
+			body:        "oops",
+			wantBody:    "oops",
+		},
+		{
+			title:       "empty body",
+			status:      http.StatusForbidden,
+			contentType: "text/plain",
+			wantIs:      docbase.ErrForbidden,
+		},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", test.contentType)
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+			client, err := docbase.NewAuthClient("example", "token").WithBaseURL(server.URL + "/")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, _, err = client.Tag.List().Do(context.Background())
+			var errResp *docbase.ErrorResponse
+			if !errors.As(err, &errResp) {
+				t.Fatalf("error = %#v, want *docbase.ErrorResponse", err)
+			}
+			if got := errResp.Retryable(); got != test.wantRetryable {
+				t.Errorf("Retryable() = %t, want %t", got, test.wantRetryable)
+			}
+			if test.wantIs != nil && !errors.Is(err, test.wantIs) {
+				t.Errorf("error = %v, want %v", err, test.wantIs)
+			}
+			if !equalStrings(errResp.Messages, test.wantMessages) {
+				t.Errorf("messages = %q, want %q", errResp.Messages, test.wantMessages)
+			}
+			if errResp.Body != test.wantBody {
+				t.Errorf("body = %q, want %q", errResp.Body, test.wantBody)
+			}
+			if errResp.ContentType != test.contentType {
+				t.Errorf("content type = %q, want %q", errResp.ContentType, test.contentType)
+			}
+		})
+	}
+}
+
+func TestRateLimitErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte("<h1>Slow down</h1>"))
+	}))
+	defer server.Close()
+	client, err := docbase.NewAuthClient("example", "token").WithBaseURL(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = client.Tag.List().Do(context.Background())
+	var rateErr *docbase.RateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("error = %#v, want *docbase.RateLimitError", err)
+	}
+	if rateErr.Body != "<h1>Slow down</h1>" || rateErr.ContentType != "text/html" {
+		t.Errorf("body = %q (%s), want the page", rateErr.Body, rateErr.ContentType)
+	}
+	if !strings.Contains(err.Error(), "Slow down") {
+		t.Errorf("error = %q, want the body in it", err)
+	}
+	if !rateErr.Retryable() {
+		t.Error("Retryable() = false, want true")
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	for _, test := range []struct {
+		title string
+		data  string
+		max   int
+		want  string
+	}{
+		{title: "short", data: " short \n", max: 10, want: "short"},
+		{title: "just the max", data: "0123456789", max: 10, want: "0123456789"},
+		{title: "long", data: "0123456789abc", max: 10, want: "0123456789..."},
+		// "あ" takes 3 bytes, so the fourth one is cut off at 10 bytes.
+		{title: "multibyte", data: "ああああ", max: 10, want: "あああ..."},
+		{title: "multibyte at the boundary", data: "0あああ", max: 10, want: "0あああ"},
+		{title: "multibyte over the boundary", data: "0ああああ", max: 10, want: "0あああ..."},
+		{title: "emoji", data: "a😀😀😀", max: 6, want: "a😀..."},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			got := docbase.Snippet([]byte(test.data), test.max)
+			if got != test.want {
+				t.Errorf("snippet = %q, want %q", got, test.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("snippet = %q, want valid UTF-8", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package docbase
+
+// Unexported functions to be tested in docbase_test.
+var (
+	Backoff     = (*RetryPolicy).backoff
+	ReserveSlot = (*RateLimiter).reserve
+	Snippet     = snippet
+)
+
+const (
+	MinRateLimitWait    = minRateLimitWait
+	MaxErrorBodySnippet = maxErrorBodySnippet
+)
+
+// Unexported doers to be named in docbase_test.
+type (
+	CommentEditDoer = commentEditDoer
+	CommentListDoer = commentListDoer
+)
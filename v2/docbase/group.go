@@ -2,6 +2,7 @@ package docbase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 )
 
@@ -61,7 +62,7 @@ func (s *groupService) List() *groupListDoer {
 // groupService.List methods.
 type groupListOptions struct {
 	// Option.
-	Name *string `json:"name,omitempty"`
+	Name *string `url:"name,omitempty"`
 
 	ListOptions
 }
@@ -69,6 +70,13 @@ type groupListOptions struct {
 type groupListDoer struct {
 	client *Client
 	opts   groupListOptions
+	limit  int64
+}
+
+// Name filters groups by the name.
+func (d *groupListDoer) Name(name string) *groupListDoer {
+	d.opts.Name = &name
+	return d
 }
 
 func (d *groupListDoer) Page(page int64) *groupListDoer {
@@ -101,6 +109,158 @@ func (d *groupListDoer) Do(ctx context.Context) ([]Group, *Response, error) {
 	return groups, resp, nil
 }
 
+// Limit sets the maximum number of groups to get with Each or All.
+func (d *groupListDoer) Limit(limit int64) *groupListDoer {
+	d.limit = limit
+	return d
+}
+
+// Each calls f for each group, walking through pages from the current page
+// until all groups are visited or the limit is reached.
+// If f returns an error, Each stops and returns it.
+func (d *groupListDoer) Each(ctx context.Context, f func(Group) error) error {
+	doer := *d
+	if doer.opts.PerPage == nil {
+		perPage := int64(defaultWalkPerPage)
+		doer.opts.PerPage = &perPage
+	}
+	var count int64
+	var pageSize int
+	return walkPages(ctx, &doer.opts.ListOptions, func(ctx context.Context) (*ListOptions, error) {
+		groups, _, err := doer.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range groups {
+			if limitReached(d.limit, count) {
+				return nil, nil
+			}
+			count++
+			if err := f(group); err != nil {
+				return nil, err
+			}
+		}
+		if limitReached(d.limit, count) {
+			return nil, nil
+		}
+		return guessNextPage(doer.opts.ListOptions, len(groups), &pageSize), nil
+	})
+}
+
+// All gets all groups, walking through pages from the current page
+// until all groups are got or the limit is reached.
+func (d *groupListDoer) All(ctx context.Context) ([]Group, error) {
+	var groups []Group
+	if err := d.Each(ctx, func(group Group) error {
+		groups = append(groups, group)
+		return nil
+	}); err != nil {
+		return groups, err
+	}
+	return groups, nil
+}
+
+// FindByName finds a group whose name is exactly the name.
+func (s *groupService) FindByName(name string) *groupFindByNameDoer {
+	return &groupFindByNameDoer{client: s.client, name: name}
+}
+
+type groupFindByNameDoer struct {
+	client *Client
+	name   string
+}
+
+// Do finds the group. If it is not found, Do returns an error wrapping
+// ErrNotFound.
+func (d *groupFindByNameDoer) Do(ctx context.Context) (*Group, error) {
+	group, err := d.client.Group.findByName(ctx, d.name)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, fmt.Errorf("%w: group %q", ErrNotFound, d.name)
+	}
+	return group, nil
+}
+
+// findByName finds a group whose name is exactly the name.
+// If it is not found, findByName returns nil without an error.
+func (s *groupService) findByName(ctx context.Context, name string) (*Group, error) {
+	var found *Group
+	errFound := errors.New("found")
+	err := s.List().Name(name).Each(ctx, func(group Group) error {
+		if group.Name != name {
+			return nil
+		}
+		found = &group
+		return errFound
+	})
+	if err != nil && err != errFound {
+		return nil, err
+	}
+	return found, nil
+}
+
+// ErrGroupMismatch is returned by Ensure when the existing group differs from
+// the desired one, since the API cannot update it.
+var ErrGroupMismatch = errors.New("group does not match")
+
+// Ensure finds a group by the name, or creates it if it does not exist.
+// It can be called repeatedly to reconcile groups idempotently.
+//
+// The Docbase API has no endpoint to update or archive groups, so Ensure
+// cannot change an existing group. If the Description is set and the existing
+// group has another description, Do returns the group with an error wrapping
+// ErrGroupMismatch; the caller should fix it in the Docbase web UI.
+func (s *groupService) Ensure(name string) *groupEnsureDoer {
+	return &groupEnsureDoer{client: s.client, name: name}
+}
+
+type groupEnsureDoer struct {
+	client      *Client
+	name        string
+	description *string
+}
+
+// Description sets the description of the group. It is used to create the
+// group, and checked against the existing group.
+func (d *groupEnsureDoer) Description(description string) *groupEnsureDoer {
+	d.description = &description
+	return d
+}
+
+// Do finds or creates the group, and reports whether it is created.
+func (d *groupEnsureDoer) Do(ctx context.Context) (*Group, bool, error) {
+	found, err := d.client.Group.findByName(ctx, d.name)
+	if err != nil {
+		return nil, false, err
+	}
+	if found != nil {
+		if d.description == nil {
+			return found, false, nil
+		}
+		// Groups in the list may not have their descriptions.
+		group, _, err := d.client.Group.Get(found.ID).Do(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		if group.Description != *d.description {
+			return group, false, fmt.Errorf("%w: group %q has the description %q, not %q", ErrGroupMismatch, d.name, group.Description, *d.description)
+		}
+		return group, false, nil
+	}
+
+	create := d.client.Group.Create(d.name)
+	if d.description != nil {
+		create.Description(*d.description)
+	}
+	group, _, err := create.Do(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	return group, true, nil
+}
+
 // Get a single group.
 //
 // Docbase API docs: https://help.docbase.io/posts/652983
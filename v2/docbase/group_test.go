@@ -0,0 +1,59 @@
+package docbase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+func TestGroupEnsure(t *testing.T) {
+	for _, test := range []struct {
+		title       string
+		name        string
+		description *string
+		wantCreated bool
+		wantDesc    string
+		wantErr     error
+	}{
+		{title: "found", name: "tenant-a", wantDesc: "Tenant A"},
+		{title: "found with the description", name: "tenant-a", description: stringPtr("Tenant A"), wantDesc: "Tenant A"},
+		{title: "description mismatch", name: "tenant-a", description: stringPtr("Tenant A'"), wantDesc: "Tenant A", wantErr: docbase.ErrGroupMismatch},
+		{title: "created", name: "tenant-b", description: stringPtr("Tenant B"), wantCreated: true, wantDesc: "Tenant B"},
+		{title: "not a prefix", name: "tenant", wantCreated: true},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			server := docbasetest.NewServer()
+			defer server.Close()
+			server.AddGroup(docbase.Group{Name: "tenant-a", Description: "Tenant A"})
+
+			doer := server.Client().Group.Ensure(test.name)
+			if test.description != nil {
+				doer.Description(*test.description)
+			}
+			group, created, err := doer.Do(context.Background())
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("error = %v, want %v", err, test.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if group == nil || group.Name != test.name || group.Description != test.wantDesc {
+				t.Fatalf("group = %+v, want %q with the description %q", group, test.name, test.wantDesc)
+			}
+			if created != test.wantCreated {
+				t.Errorf("created = %t, want %t", created, test.wantCreated)
+			}
+			if _, ok := server.Group(group.ID); !ok {
+				t.Errorf("group %d is not stored", group.ID)
+			}
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
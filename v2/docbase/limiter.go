@@ -0,0 +1,143 @@
+package docbase
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter paces requests so that they never exceed the rate limit.
+// It spreads the remaining requests in the current rate limit window evenly
+// until the reset time, which is learned from the most recent responses.
+//
+// A RateLimiter is safe for concurrent use, so workers sharing a Client (or
+// several Clients sharing a RateLimiter) are paced together.
+type RateLimiter struct {
+	// Reserve is the number of requests kept in reserve in each rate limit
+	// window. Only requests with a context made by Interactive can use them,
+	// and they are sent without pacing.
+	Reserve int64
+
+	mu      sync.Mutex
+	rate    Rate      // last known rate limit
+	pending int64     // number of requests sent but not responded yet
+	next    time.Time // earliest time to send the next paced request
+}
+
+// NewRateLimiter creates a RateLimiter which keeps the reserve.
+func NewRateLimiter(reserve int64) *RateLimiter {
+	return &RateLimiter{Reserve: reserve}
+}
+
+type interactiveKey struct{}
+
+// Interactive returns a context which marks requests made with it as
+// interactive. Interactive requests are not paced and can use the Reserve of
+// the RateLimiter.
+func Interactive(ctx context.Context) context.Context {
+	return context.WithValue(ctx, interactiveKey{}, true)
+}
+
+func isInteractive(ctx context.Context) bool {
+	v, _ := ctx.Value(interactiveKey{}).(bool)
+	return v
+}
+
+// Update tells the limiter the rate limit learned from a response.
+// Rates without limit (e.g. from a response without rate limit headers) are
+// ignored.
+func (l *RateLimiter) Update(rate Rate) {
+	if rate.Limit == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = rate
+}
+
+// done tells the limiter that a request sent after Wait is finished, with the
+// rate limit learned from its response (if any).
+func (l *RateLimiter) done(rate Rate) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.pending > 0 {
+		l.pending--
+	}
+	if rate.Limit != 0 {
+		l.rate = rate
+	}
+}
+
+// seed sets the rate if the limiter does not know any rate yet.
+func (l *RateLimiter) seed(rate Rate) {
+	if rate.Limit == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate.Limit == 0 {
+		l.rate = rate
+	}
+}
+
+// Wait blocks until a request can be sent.
+// If ctx is done before that, it returns ctx.Err().
+// After Wait returns nil, the request must be finished with done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		wait, ok := l.reserve(time.Now(), isInteractive(ctx))
+		l.mu.Unlock()
+		if ok {
+			if wait <= 0 {
+				return nil
+			}
+			if err := sleep(ctx, wait); err != nil {
+				l.done(Rate{})
+				return err
+			}
+			return nil
+		}
+		// The budget is exhausted: wait for the reset and try again.
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve books a slot to send a request, and returns the duration to wait
+// for the slot. If there's no slot in the current window, it returns false
+// with the duration until the reset.
+func (l *RateLimiter) reserve(now time.Time, interactive bool) (time.Duration, bool) {
+	reset := l.rate.Reset.Time
+	if l.rate.Limit == 0 || !now.Before(reset) {
+		// The rate limit is unknown or has been reset; a response will tell
+		// the new one.
+		l.pending++
+		return 0, true
+	}
+
+	budget := l.rate.Remaining - l.pending
+	if !interactive {
+		budget -= l.Reserve
+	}
+	if budget <= 0 {
+		wait := reset.Sub(now)
+		if wait < minRateLimitWait {
+			wait = minRateLimitWait
+		}
+		return wait, false
+	}
+
+	l.pending++
+	if interactive {
+		return 0, true
+	}
+
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(reset.Sub(slot) / time.Duration(budget))
+	return slot.Sub(now), true
+}
@@ -0,0 +1,108 @@
+package docbase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	rate := func(remaining int64, reset time.Duration) docbase.Rate {
+		return docbase.Rate{Limit: 300, Remaining: remaining, Reset: docbase.Timestamp{Time: now.Add(reset)}}
+	}
+	type slot struct {
+		interactive bool
+		wait        time.Duration
+		ok          bool
+	}
+	for _, test := range []struct {
+		title   string
+		rate    docbase.Rate
+		reserve int64
+		slots   []slot
+	}{
+		{
+			title: "unknown rate",
+			slots: []slot{{wait: 0, ok: true}, {wait: 0, ok: true}},
+		},
+		{
+			title: "reset",
+			rate:  rate(0, -time.Second),
+			slots: []slot{{wait: 0, ok: true}},
+		},
+		{
+			title: "paced evenly",
+			rate:  rate(10, 10*time.Second),
+			slots: []slot{
+				{wait: 0, ok: true},
+				{wait: time.Second, ok: true},
+				// 9s from the second slot is left for 9 requests.
+				{wait: 2 * time.Second, ok: true},
+			},
+		},
+		{
+			title: "exhausted",
+			rate:  rate(1, 10*time.Second),
+			slots: []slot{{wait: 0, ok: true}, {wait: 10 * time.Second, ok: false}},
+		},
+		{
+			title: "exhausted just before the reset",
+			rate:  rate(0, time.Millisecond),
+			slots: []slot{{wait: docbase.MinRateLimitWait, ok: false}},
+		},
+		{
+			title:   "reserved",
+			rate:    rate(3, 10*time.Second),
+			reserve: 2,
+			slots: []slot{
+				{wait: 0, ok: true},
+				{wait: 10 * time.Second, ok: false},
+				{interactive: true, wait: 0, ok: true},
+				{interactive: true, wait: 0, ok: true},
+				{interactive: true, wait: 10 * time.Second, ok: false},
+			},
+		},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			limiter := docbase.NewRateLimiter(test.reserve)
+			limiter.Update(test.rate)
+			for i, s := range test.slots {
+				wait, ok := docbase.ReserveSlot(limiter, now, s.interactive)
+				if wait != s.wait || ok != s.ok {
+					t.Errorf("slot %d: got %s, %t; want %s, %t", i, wait, ok, s.wait, s.ok)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterWithServer(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	server.SetRate(3, time.Now().Add(time.Hour))
+	client := server.Client()
+	client.RateLimiter = docbase.NewRateLimiter(2)
+
+	ctx := context.Background()
+	// The first request learns the rate limit.
+	if _, _, err := client.Tag.List().Do(docbase.Interactive(ctx)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The rest are reserved for interactive requests.
+	timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, _, err := client.Tag.List().Do(timeout); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("paced request: error = %v, want context.DeadlineExceeded", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, _, err := client.Tag.List().Do(docbase.Interactive(ctx)); err != nil {
+			t.Errorf("interactive request %d: %s", i, err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package docbase
+
+import "context"
+
+// defaultWalkPerPage is the number of items in a page to walk through pages
+// of endpoints which do not respond pagination meta.
+const defaultWalkPerPage = 100
+
+// walkPages calls page for each page from the page specified in opts.
+// page should return ListOptions for the next page, or nil for the last page.
+// If ctx is canceled or times out, walkPages stops with ctx.Err().
+func walkPages(ctx context.Context, opts *ListOptions, page func(ctx context.Context) (*ListOptions, error)) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		next, err := page(ctx)
+		if err != nil {
+			return err
+		}
+		if next == nil {
+			return nil
+		}
+		*opts = *next
+	}
+}
+
+// guessNextPage builds ListOptions for the next page from the number of items
+// in the current page, for endpoints which do not respond pagination meta.
+// The server may return fewer items than the requested per_page in every
+// page, so a page is taken as the last one only if it is empty or smaller
+// than a page before. pageSize holds the largest number of items in the
+// pages so far. If there is no next page, it will be nil.
+func guessNextPage(opts ListOptions, count int, pageSize *int) *ListOptions {
+	if count == 0 || count < *pageSize {
+		return nil
+	}
+	*pageSize = count
+	page := int64(1)
+	if opts.Page != nil {
+		page = *opts.Page
+	}
+	page++
+	next := ListOptions{Page: &page}
+	if opts.PerPage != nil {
+		perPage := *opts.PerPage
+		next.PerPage = &perPage
+	}
+	return &next
+}
+
+// limitReached checks whether count reaches the limit.
+// A limit less than or equal to zero means no limit.
+func limitReached(limit, count int64) bool {
+	return limit > 0 && count >= limit
+}
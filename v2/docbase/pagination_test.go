@@ -0,0 +1,78 @@
+package docbase_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+func TestListAll(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	// The server has the owner besides them.
+	for i := 0; i < 149; i++ {
+		server.AddUser(docbase.User{Username: fmt.Sprintf("user%d", i)})
+		server.AddGroup(docbase.Group{Name: fmt.Sprintf("group%d", i)})
+		server.AddPost(docbase.Post{Title: fmt.Sprintf("post%d", i), Body: "body"})
+	}
+	client := server.Client()
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		title   string
+		perPage int64 // 0 for the default
+		limit   int64
+		want    int
+	}{
+		{title: "default", want: 150},
+		{title: "small pages", perPage: 7, want: 150},
+		// The server caps per_page at 100.
+		{title: "capped pages", perPage: 150, want: 150},
+		{title: "limit", perPage: 7, limit: 10, want: 10},
+		{title: "limit over pages", perPage: 100, limit: 120, want: 120},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			users := client.User.List().Limit(test.limit)
+			groups := client.Group.List().Limit(test.limit)
+			posts := client.Post.List().Limit(test.limit)
+			if test.perPage > 0 {
+				users.PerPage(test.perPage)
+				groups.PerPage(test.perPage)
+				posts.PerPage(test.perPage)
+			}
+
+			gotUsers, err := users.All(ctx)
+			if err != nil {
+				t.Fatalf("users: %s", err)
+			}
+			if len(gotUsers) != test.want {
+				t.Errorf("users: got %d, want %d", len(gotUsers), test.want)
+			}
+			gotGroups, err := groups.All(ctx)
+			if err != nil {
+				t.Fatalf("groups: %s", err)
+			}
+			// The owner is not a group.
+			if want := min(test.want, 149); len(gotGroups) != want {
+				t.Errorf("groups: got %d, want %d", len(gotGroups), want)
+			}
+			gotPosts, err := posts.All(ctx)
+			if err != nil {
+				t.Fatalf("posts: %s", err)
+			}
+			if want := min(test.want, 149); len(gotPosts) != want {
+				t.Errorf("posts: got %d, want %d", len(gotPosts), want)
+			}
+		})
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
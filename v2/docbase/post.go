@@ -33,10 +33,26 @@ type postListOptions struct {
 type postListDoer struct {
 	client *Client
 	opts   postListOptions
+	filter PostQuery
+	limit  int64
+}
+
+// PostQuery is a structured query to search posts (e.g. *postquery.Query).
+type PostQuery interface {
+	String() string
+	Validate() error
 }
 
 func (d *postListDoer) Query(query string) *postListDoer {
 	d.opts.Query = &query
+	d.filter = nil
+	return d
+}
+
+// Filter sets a structured query to search posts.
+// It is validated and rendered in Do.
+func (d *postListDoer) Filter(query PostQuery) *postListDoer {
+	d.filter = query
 	return d
 }
 
@@ -51,7 +67,15 @@ func (d *postListDoer) PerPage(perPage int64) *postListDoer {
 }
 
 func (d *postListDoer) Do(ctx context.Context) ([]Post, *Response, error) {
-	u, err := addOptions("posts", d.opts)
+	opts := d.opts
+	if d.filter != nil {
+		if err := d.filter.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("invalid query: %w", err)
+		}
+		query := d.filter.String()
+		opts.Query = &query
+	}
+	u, err := addOptions("posts", opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -74,6 +98,52 @@ func (d *postListDoer) Do(ctx context.Context) ([]Post, *Response, error) {
 	return posts.Posts, resp, nil
 }
 
+// Limit sets the maximum number of posts to get with Each or All.
+func (d *postListDoer) Limit(limit int64) *postListDoer {
+	d.limit = limit
+	return d
+}
+
+// Each calls f for each post, walking through pages from the current page
+// until all posts are visited or the limit is reached.
+// If f returns an error, Each stops and returns it.
+func (d *postListDoer) Each(ctx context.Context, f func(Post) error) error {
+	doer := *d
+	var count int64
+	return walkPages(ctx, &doer.opts.ListOptions, func(ctx context.Context) (*ListOptions, error) {
+		posts, resp, err := doer.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, post := range posts {
+			if limitReached(d.limit, count) {
+				return nil, nil
+			}
+			count++
+			if err := f(post); err != nil {
+				return nil, err
+			}
+		}
+		if limitReached(d.limit, count) {
+			return nil, nil
+		}
+		return resp.Meta.Next(), nil
+	})
+}
+
+// All gets all posts, walking through pages from the current page
+// until all posts are got or the limit is reached.
+func (d *postListDoer) All(ctx context.Context) ([]Post, error) {
+	var posts []Post
+	if err := d.Each(ctx, func(post Post) error {
+		posts = append(posts, post)
+		return nil
+	}); err != nil {
+		return posts, err
+	}
+	return posts, nil
+}
+
 // Get a single post.
 //
 // Docbase API docs: https://help.docbase.io/posts/97204
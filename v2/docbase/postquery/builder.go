@@ -0,0 +1,146 @@
+package postquery
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Query is a structured query to search posts.
+// Clauses are joined with AND, and terms in each clause are joined with OR.
+type Query struct {
+	Clauses []Clause
+	Sort    *SortOrder
+}
+
+// Clause is a group of terms joined with OR.
+type Clause []Term
+
+// SortOrder specifies a sort order of posts.
+type SortOrder struct {
+	Property SortName
+	Asc      bool
+}
+
+func (s SortOrder) String() string {
+	return Sort(s.Property, s.Asc)
+}
+
+// New creates a Query to search posts which match all of the terms.
+func New(terms ...Term) *Query {
+	return new(Query).And(terms...)
+}
+
+// And adds terms which posts should match all of.
+func (q *Query) And(terms ...Term) *Query {
+	for _, term := range terms {
+		q.Clauses = append(q.Clauses, Clause{term})
+	}
+	return q
+}
+
+// AnyOf adds a group of terms which posts should match any of.
+func (q *Query) AnyOf(terms ...Term) *Query {
+	q.Clauses = append(q.Clauses, Clause(terms))
+	return q
+}
+
+// SortBy sets the sort order of posts.
+func (q *Query) SortBy(property SortName, asc bool) *Query {
+	q.Sort = &SortOrder{Property: property, Asc: asc}
+	return q
+}
+
+func (c Clause) String() string {
+	terms := make([]string, 0, len(c))
+	for _, term := range c {
+		terms = append(terms, term.String())
+	}
+	return strings.Join(terms, " "+Or()+" ")
+}
+
+// String renders the query in the Docbase query syntax.
+func (q *Query) String() string {
+	queries := make([]string, 0, len(q.Clauses)+1)
+	for _, clause := range q.Clauses {
+		queries = append(queries, clause.String())
+	}
+	if q.Sort != nil {
+		queries = append(queries, q.Sort.String())
+	}
+	return Join(queries...)
+}
+
+// Validate checks whether the query can be searched.
+// It rejects invalid terms and terms which no post can match at once
+// (e.g. "tag:foo -tag:foo", "missing:tag tag:foo" or disjoint date ranges).
+func (q *Query) Validate() error {
+	for i, clause := range q.Clauses {
+		if len(clause) == 0 {
+			return fmt.Errorf("clause %d has no term", i)
+		}
+		for _, term := range clause {
+			if term == nil {
+				return fmt.Errorf("clause %d has a nil term", i)
+			}
+			if err := term.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	if q.Sort != nil && !q.Sort.Property.valid() {
+		return fmt.Errorf("unknown sort property %q", q.Sort.Property)
+	}
+	return q.checkContradiction()
+}
+
+// checkContradiction finds terms required at once but unable to be matched
+// at once.
+func (q *Query) checkContradiction() error {
+	required := map[string]Term{}
+	var missingTag, hasTag Term
+	ranges := map[DateName]DateFilter{}
+	for _, clause := range q.Clauses {
+		if len(clause) != 1 {
+			continue
+		}
+		term := clause[0]
+		if opposite, ok := required[Not(term).String()]; ok {
+			return fmt.Errorf("%q contradicts %q", term, opposite)
+		}
+		required[term.String()] = term
+
+		switch t := term.(type) {
+		case MissingFilter:
+			if !t.Negated && t.Property == MissingNameTag {
+				missingTag = t
+			}
+		case PropertyFilter:
+			if !t.Negated && t.Property == PropertyNameTag {
+				hasTag = t
+			}
+		case DateFilter:
+			if t.Negated {
+				continue
+			}
+			r, ok := ranges[t.Property]
+			if !ok {
+				ranges[t.Property] = t
+				continue
+			}
+			if !t.From.IsZero() && (r.From.IsZero() || t.From.Format(dateLayout) > r.From.Format(dateLayout)) {
+				r.From = t.From
+			}
+			if !t.To.IsZero() && (r.To.IsZero() || t.To.Format(dateLayout) < r.To.Format(dateLayout)) {
+				r.To = t.To
+			}
+			if err := r.Validate(); err != nil {
+				return fmt.Errorf("%q and other ranges for %s have no date in common", t, t.Property)
+			}
+			ranges[t.Property] = r
+		}
+	}
+	if missingTag != nil && hasTag != nil {
+		return fmt.Errorf("%q contradicts %q", missingTag, hasTag)
+	}
+	return nil
+}
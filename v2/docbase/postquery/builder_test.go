@@ -0,0 +1,56 @@
+package postquery_test
+
+import (
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase/postquery"
+)
+
+func TestQueryValidate(t *testing.T) {
+	draft := postquery.StateFilter{State: postquery.StateDraft}
+	tag := func(value string) postquery.Term {
+		return postquery.PropertyFilter{Property: postquery.PropertyNameTag, Value: value}
+	}
+	missingTag := postquery.MissingFilter{Property: postquery.MissingNameTag}
+	created := func(from, to string) postquery.Term {
+		return postquery.DateFilter{Property: postquery.DateNameCreatedAt, From: date(t, from), To: date(t, to)}
+	}
+	changed := func(from, to string) postquery.Term {
+		return postquery.DateFilter{Property: postquery.DateNameChangedAt, From: date(t, from), To: date(t, to)}
+	}
+	for _, test := range []struct {
+		title   string
+		query   *postquery.Query
+		wantErr bool
+	}{
+		{title: "empty", query: postquery.New()},
+		{title: "terms", query: postquery.New(draft, tag("go"), created("2019-01-01", ""))},
+		{title: "draft and published", query: postquery.New(draft, postquery.Not(draft)), wantErr: true},
+		{title: "draft or published", query: new(postquery.Query).AnyOf(draft, postquery.Not(draft))},
+		{title: "tag and not tag", query: postquery.New(tag("go"), postquery.Not(tag("go"))), wantErr: true},
+		{title: "different tags", query: postquery.New(tag("go"), postquery.Not(tag("rust")))},
+		{title: "raw and not raw", query: postquery.New(postquery.Raw{Text: "foo:bar"}, postquery.Raw{Text: "foo:bar", Negated: true}), wantErr: true},
+		{title: "missing tag and tag", query: postquery.New(missingTag, tag("go")), wantErr: true},
+		{title: "missing tag and not tag", query: postquery.New(missingTag, postquery.Not(tag("go")))},
+		{title: "missing tag or tag", query: new(postquery.Query).AnyOf(missingTag, tag("go"))},
+		{title: "overlapped ranges", query: postquery.New(created("2019-01-01", "2019-01-31"), created("2019-01-31", "2019-02-28"))},
+		{title: "disjoint ranges", query: postquery.New(created("2019-01-01", "2019-01-31"), created("2019-02-01", "2019-02-28")), wantErr: true},
+		{title: "disjoint open ranges", query: postquery.New(created("", "2019-01-31"), created("2019-02-01", "")), wantErr: true},
+		{title: "disjoint dates", query: postquery.New(created("2019-01-01", "2019-01-01"), created("2019-01-02", "2019-01-02")), wantErr: true},
+		{title: "ranges of different properties", query: postquery.New(created("2019-01-01", "2019-01-31"), changed("2019-02-01", "2019-02-28"))},
+		{title: "negated disjoint range", query: postquery.New(created("2019-01-01", "2019-01-31"), postquery.Not(created("2019-02-01", "2019-02-28")))},
+		{title: "disjoint ranges in a clause", query: new(postquery.Query).AnyOf(created("2019-01-01", "2019-01-31"), created("2019-02-01", "2019-02-28"))},
+		{title: "reversed range", query: postquery.New(created("2019-02-01", "2019-01-01")), wantErr: true},
+		{title: "invalid term", query: postquery.New(postquery.Keyword{Text: " "}), wantErr: true},
+		{title: "empty clause", query: new(postquery.Query).AnyOf(), wantErr: true},
+		{title: "nil term", query: postquery.New(nil), wantErr: true},
+		{title: "unknown sort", query: postquery.New().SortBy("unknown", true), wantErr: true},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			err := test.query.Validate()
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("Validate() = %v, want error: %t", err, test.wantErr)
+			}
+		})
+	}
+}
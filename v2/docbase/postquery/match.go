@@ -0,0 +1,180 @@
+package postquery
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+// ErrUnsupported is returned when a term cannot be evaluated locally, because
+// it depends on information which a Post does not have (e.g. "liked_by:",
+// "is:unread").
+var ErrUnsupported = errors.New("unsupported term")
+
+// Match reports whether the post matches the query in the same way as the
+// Docbase search does.
+// The sort order is ignored.
+// If the query has a term which cannot be evaluated locally, it returns an
+// error wrapping ErrUnsupported.
+func (q *Query) Match(post *docbase.Post) (bool, error) {
+	matched := true
+	for _, clause := range q.Clauses {
+		ok, err := clause.match(post)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			// Continue to check the rest of the clauses for unsupported terms.
+			matched = false
+		}
+	}
+	return matched, nil
+}
+
+// Filter gets posts which match the query, keeping their order.
+func (q *Query) Filter(posts []docbase.Post) ([]docbase.Post, error) {
+	var matched []docbase.Post
+	for i := range posts {
+		ok, err := q.Match(&posts[i])
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, posts[i])
+		}
+	}
+	return matched, nil
+}
+
+func (c Clause) match(post *docbase.Post) (bool, error) {
+	matched := false
+	for _, term := range c {
+		ok, err := matchTerm(term, post)
+		if err != nil {
+			return false, err
+		}
+		matched = matched || ok
+	}
+	return matched, nil
+}
+
+func matchTerm(term Term, post *docbase.Post) (bool, error) {
+	var (
+		ok      bool
+		negated bool
+		err     error
+	)
+	switch t := term.(type) {
+	case Keyword:
+		ok, negated = matchKeyword(t.Text, post), t.Negated
+	case PropertyFilter:
+		ok, err = matchProperty(t, post)
+		negated = t.Negated
+	case DateFilter:
+		ok, err = matchDate(t, post)
+		negated = t.Negated
+	case MissingFilter:
+		ok, negated = len(post.Tags) == 0, t.Negated
+	case StateFilter:
+		ok, err = matchState(t, post)
+		negated = t.Negated
+	default:
+		err = fmt.Errorf("%w: %v", ErrUnsupported, term)
+	}
+	if err != nil {
+		return false, err
+	}
+	return ok != negated, nil
+}
+
+// contains checks whether s contains substr, ignoring case.
+func contains(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func matchKeyword(text string, post *docbase.Post) bool {
+	if contains(post.Title, text) || contains(post.Body, text) {
+		return true
+	}
+	for _, comment := range post.Comments {
+		if contains(comment.Body, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchProperty(t PropertyFilter, post *docbase.Post) (bool, error) {
+	switch t.Property {
+	case PropertyNameTitle:
+		return contains(post.Title, t.Value), nil
+	case PropertyNameBody:
+		return contains(post.Body, t.Value), nil
+	case PropertyNameComments:
+		for _, comment := range post.Comments {
+			if contains(comment.Body, t.Value) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case PropertyNameAuthor:
+		return strings.EqualFold(post.User.Username, t.Value), nil
+	case PropertyNameCommentedBy:
+		for _, comment := range post.Comments {
+			if strings.EqualFold(comment.User.Username, t.Value) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case PropertyNameTag:
+		for _, tag := range post.Tags {
+			if strings.EqualFold(tag.Name, t.Value) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case PropertyNameGroup:
+		for _, group := range post.Groups {
+			if strings.EqualFold(group.Name, t.Value) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("%w: %v", ErrUnsupported, t)
+}
+
+func matchDate(t DateFilter, post *docbase.Post) (bool, error) {
+	value := post.CreatedAt
+	if t.Property == DateNameChangedAt && post.UpdatedAt != "" {
+		value = post.UpdatedAt
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s of the post %d: %w", t.Property, post.ID, err)
+	}
+	// Compare by day in the location of each time.
+	date := at.Format(dateLayout)
+	if !t.From.IsZero() && date < t.From.Format(dateLayout) {
+		return false, nil
+	}
+	if !t.To.IsZero() && date > t.To.Format(dateLayout) {
+		return false, nil
+	}
+	return true, nil
+}
+
+func matchState(t StateFilter, post *docbase.Post) (bool, error) {
+	switch t.State {
+	case StateDraft:
+		return post.Draft, nil
+	case StateArchived:
+		return post.Archived, nil
+	case StateShared:
+		return post.SharingURL != "", nil
+	}
+	return false, fmt.Errorf("%w: %v", ErrUnsupported, t)
+}
@@ -0,0 +1,131 @@
+package postquery_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+	"github.com/kyoh86/go-docbase/v2/docbase/postquery"
+)
+
+func TestMatch(t *testing.T) {
+	post := &docbase.Post{
+		Title:      "Go Runbook",
+		Body:       "Restart the server.",
+		Draft:      true,
+		SharingURL: "",
+		CreatedAt:  "2019-01-15T23:30:00+09:00",
+		UpdatedAt:  "2019-02-01T10:00:00+09:00",
+		User:       docbase.User{Username: "alice"},
+		Tags:       []docbase.Tag{{Name: "go"}, {Name: "ops"}},
+		Groups:     []docbase.Group{{Name: "dev"}},
+		Comments:   []docbase.Comment{{Body: "LGTM", User: docbase.User{Username: "bob"}}},
+	}
+	untagged := &docbase.Post{Title: "memo", CreatedAt: "2019-01-15T00:00:00Z", Tags: []docbase.Tag{}}
+
+	for _, test := range []struct {
+		query string
+		post  *docbase.Post
+		want  bool
+	}{
+		{query: "", post: post, want: true},
+		{query: "runbook", post: post, want: true},
+		{query: "restart", post: post, want: true},
+		{query: "lgtm", post: post, want: true},
+		{query: "-runbook", post: post, want: false},
+		{query: "runbook missing", post: post, want: false},
+		{query: "runbook OR missing", post: post, want: true},
+		{query: "title:runbook", post: post, want: true},
+		{query: "body:runbook", post: post, want: false},
+		{query: "comments:lgtm", post: post, want: true},
+		{query: "author:ALICE", post: post, want: true},
+		{query: "commented_by:bob", post: post, want: true},
+		{query: "tag:go", post: post, want: true},
+		{query: "tag:g", post: post, want: false},
+		{query: "-tag:rust group:dev", post: post, want: true},
+		{query: "missing:tag", post: post, want: false},
+		{query: "missing:tag", post: untagged, want: true},
+		{query: "is:draft", post: post, want: true},
+		{query: "-is:draft", post: untagged, want: true},
+		{query: "is:shared", post: post, want: false},
+		{query: "is:archived", post: post, want: false},
+		{query: "is:archived", post: &docbase.Post{Archived: true}, want: true},
+		// Dates are compared in the location of each time.
+		{query: "created_at:2019-01-15", post: post, want: true},
+		{query: "created_at:2019-01-16~*", post: post, want: false},
+		{query: "changed_at:*~2019-01-31", post: post, want: false},
+		{query: "changed_at:2019-01-31~2019-02-01", post: post, want: true},
+		{query: "changed_at:2019-01-15", post: untagged, want: true},
+		// The sort order is ignored.
+		{query: "tag:ops desc:stars", post: post, want: true},
+	} {
+		t.Run(test.query, func(t *testing.T) {
+			query, err := postquery.Parse(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := query.Match(test.post)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMatchUnsupported(t *testing.T) {
+	post := &docbase.Post{Title: "memo", CreatedAt: "2019-01-15T00:00:00Z"}
+	for _, query := range []string{
+		"has:star",
+		"is:unread",
+		"liked_by:alice",
+		"attachments:manual",
+		"foo:bar",
+		// An unsupported term is reported even if the other clause fails.
+		"tag:none liked_by:alice",
+	} {
+		t.Run(query, func(t *testing.T) {
+			q, err := postquery.Parse(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := q.Match(post); !errors.Is(err, postquery.ErrUnsupported) {
+				t.Errorf("error = %v, want ErrUnsupported", err)
+			}
+		})
+	}
+}
+
+func TestMatchWithServer(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	server.AddPost(docbase.Post{Title: "go", Body: "b", Tags: []docbase.Tag{{Name: "go"}}})
+	server.AddPost(docbase.Post{Title: "rust", Body: "b", Tags: []docbase.Tag{{Name: "rust"}}})
+	client := server.Client()
+
+	for _, test := range []struct {
+		query string
+		want  int
+	}{
+		{query: "tag:go", want: 1},
+		// Terms which the server cannot evaluate are ignored.
+		{query: "has:star", want: 2},
+		{query: "tag:go -is:unread", want: 1},
+		{query: "tag:go liked_by:alice", want: 1},
+		{query: "tag:none OR has:star", want: 2},
+	} {
+		t.Run(test.query, func(t *testing.T) {
+			posts, _, err := client.Post.List().Query(test.query).Do(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(posts) != test.want {
+				t.Errorf("got %d posts, want %d", len(posts), test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package postquery
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Parse parses a Docbase query string into a Query.
+// Dates in the query are parsed in UTC.
+//
+// The Query renders a string equivalent to the query string, so it can be
+// used to inspect or rewrite the query and to send it back to the API.
+func Parse(query string) (*Query, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	q := new(Query)
+	var (
+		clause  Clause
+		pending bool // an OR is waiting for its right-hand term
+	)
+	for _, tok := range tokens {
+		if tok.isOr() {
+			if len(clause) == 0 || pending {
+				return nil, errors.New("OR must be placed between terms")
+			}
+			pending = true
+			continue
+		}
+
+		if sort, ok, err := tok.sortOrder(); err != nil {
+			return nil, err
+		} else if ok {
+			if pending {
+				return nil, fmt.Errorf("sort order %q cannot be joined with OR", tok.raw)
+			}
+			if q.Sort != nil {
+				return nil, fmt.Errorf("sort order %q is specified twice", tok.raw)
+			}
+			q.Sort = sort
+			continue
+		}
+
+		term, err := tok.term()
+		if err != nil {
+			return nil, err
+		}
+		if !pending && len(clause) > 0 {
+			q.Clauses = append(q.Clauses, clause)
+			clause = nil
+		}
+		clause = append(clause, term)
+		pending = false
+	}
+	if pending {
+		return nil, errors.New("OR must be placed between terms")
+	}
+	if len(clause) > 0 {
+		q.Clauses = append(q.Clauses, clause)
+	}
+	return q, nil
+}
+
+// token is a raw element of a query string.
+type token struct {
+	raw     string // the token without negation
+	negated bool
+}
+
+// tokenize splits a query string by spaces which are not quoted.
+func tokenize(query string) ([]token, error) {
+	var (
+		tokens  []token
+		buf     strings.Builder
+		quoted  bool
+		escaped bool
+	)
+	flush := func() {
+		if buf.Len() == 0 {
+			return
+		}
+		raw := buf.String()
+		buf.Reset()
+		tok := token{raw: raw}
+		if len(raw) > 1 && raw[0] == '-' {
+			tok.raw, tok.negated = raw[1:], true
+		}
+		tokens = append(tokens, tok)
+	}
+	for _, r := range query {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case !quoted && unicode.IsSpace(r):
+			flush()
+			continue
+		}
+		buf.WriteRune(r)
+	}
+	if quoted {
+		return nil, errors.New("quotation is not closed")
+	}
+	flush()
+	return tokens, nil
+}
+
+func (t token) isOr() bool {
+	return !t.negated && t.raw == Or()
+}
+
+// split splits the token into a property name and a value.
+// If the token has no property name, the name will be empty.
+func (t token) split() (string, string) {
+	if strings.HasPrefix(t.raw, `"`) {
+		return "", unquote(t.raw)
+	}
+	i := strings.IndexRune(t.raw, ':')
+	if i < 0 {
+		return "", t.raw
+	}
+	return t.raw[:i], unquote(t.raw[i+1:])
+}
+
+// unquote removes quotation from the value if it is quoted.
+func unquote(value string) string {
+	if len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		return value
+	}
+	value = value[1 : len(value)-1]
+	var buf strings.Builder
+	escaped := false
+	for _, r := range value {
+		if !escaped && r == '\\' {
+			escaped = true
+			continue
+		}
+		escaped = false
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+func (t token) sortOrder() (*SortOrder, bool, error) {
+	name, value := t.split()
+	if name != "asc" && name != "desc" {
+		return nil, false, nil
+	}
+	if t.negated {
+		return nil, false, fmt.Errorf("sort order %q cannot be negated", t.raw)
+	}
+	sort := &SortOrder{Property: SortName(value), Asc: name == "asc"}
+	if !sort.Property.valid() {
+		return nil, false, fmt.Errorf("unknown sort property %q", value)
+	}
+	return sort, true, nil
+}
+
+func (t token) term() (Term, error) {
+	name, value := t.split()
+	var term Term
+	switch {
+	case name == "":
+		term = Keyword{Text: value}
+
+	case name == "missing":
+		term = MissingFilter{Property: MissingName(value)}
+
+	case name == "is" || name == "has":
+		term = StateFilter{State: StateName(name + ":" + value)}
+
+	case DateName(name).valid():
+		from, to, err := parseDateRange(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date in %q: %w", t.raw, err)
+		}
+		term = DateFilter{Property: DateName(name), From: from, To: to}
+
+	case PropertyName(name).valid():
+		term = PropertyFilter{Property: PropertyName(name), Value: value}
+
+	default:
+		// Unknown properties are kept as they are, to be sent to Docbase.
+		term = Raw{Text: t.raw}
+	}
+	if err := term.Validate(); err != nil {
+		return nil, err
+	}
+	if t.negated {
+		term = Not(term)
+	}
+	return term, nil
+}
+
+// parseDateRange parses a date ("2019-01-31") or a range of dates
+// ("2019-01-01~2019-01-31", "*~2019-01-31" or "2019-01-01~*").
+func parseDateRange(value string) (time.Time, time.Time, error) {
+	parse := func(s string) (time.Time, error) {
+		if s == "*" {
+			return time.Time{}, nil
+		}
+		return time.Parse(dateLayout, s)
+	}
+	parts := strings.SplitN(value, "~", 2)
+	from, err := parse(parts[0])
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if len(parts) == 1 {
+		return from, from, nil
+	}
+	to, err := parse(parts[1])
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return from, to, nil
+}
@@ -0,0 +1,224 @@
+package postquery_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+	"github.com/kyoh86/go-docbase/v2/docbase/postquery"
+)
+
+func TestParse(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	for _, test := range []struct {
+		query string
+		want  *postquery.Query
+		// str is the rendered query if it differs from the query.
+		str string
+	}{
+		{
+			query: "",
+			want:  &postquery.Query{},
+		},
+		{
+			query: "foo bar",
+			want:  postquery.New(postquery.Keyword{Text: "foo"}, postquery.Keyword{Text: "bar"}),
+		},
+		{
+			query: `"foo bar" -baz`,
+			want:  postquery.New(postquery.Keyword{Text: "foo bar"}, postquery.Keyword{Text: "baz", Negated: true}),
+		},
+		{
+			query: `title:"a \"quoted\" title" -tag:draft`,
+			want: postquery.New(
+				postquery.PropertyFilter{Property: postquery.PropertyNameTitle, Value: `a "quoted" title`},
+				postquery.PropertyFilter{Property: postquery.PropertyNameTag, Value: "draft", Negated: true},
+			),
+		},
+		{
+			query: "tag:go OR tag:rust author:alice",
+			want: new(postquery.Query).
+				AnyOf(
+					postquery.PropertyFilter{Property: postquery.PropertyNameTag, Value: "go"},
+					postquery.PropertyFilter{Property: postquery.PropertyNameTag, Value: "rust"},
+				).
+				And(postquery.PropertyFilter{Property: postquery.PropertyNameAuthor, Value: "alice"}),
+		},
+		{
+			query: "created_at:2019-01-01~2019-01-31 changed_at:*~2020-02-01 created_at:2018-05-05",
+			want: postquery.New(
+				postquery.DateFilter{Property: postquery.DateNameCreatedAt, From: date("2019-01-01"), To: date("2019-01-31")},
+				postquery.DateFilter{Property: postquery.DateNameChangedAt, To: date("2020-02-01")},
+				postquery.DateFilter{Property: postquery.DateNameCreatedAt, From: date("2018-05-05"), To: date("2018-05-05")},
+			),
+		},
+		{
+			query: "missing:tag -is:draft has:star desc:changed_at",
+			want: postquery.New(
+				postquery.MissingFilter{Property: postquery.MissingNameTag},
+				postquery.StateFilter{State: postquery.StateDraft, Negated: true},
+				postquery.StateFilter{State: postquery.StateStarred},
+			).SortBy(postquery.SortNameChangedAt, false),
+		},
+		{
+			query: "asc:stars foo",
+			want:  postquery.New(postquery.Keyword{Text: "foo"}).SortBy(postquery.SortNameStars, true),
+			str:   "foo asc:stars",
+		},
+		{
+			// Unknown properties are kept as they are.
+			query: `foo:bar -baz:"a b"`,
+			want:  postquery.New(postquery.Raw{Text: "foo:bar"}, postquery.Raw{Text: `baz:"a b"`, Negated: true}),
+		},
+		{
+			query: "is:archived OR foo:bar",
+			want: new(postquery.Query).AnyOf(
+				postquery.StateFilter{State: postquery.StateArchived},
+				postquery.Raw{Text: "foo:bar"},
+			),
+		},
+		{
+			query: "  foo   bar  ",
+			want:  postquery.New(postquery.Keyword{Text: "foo"}, postquery.Keyword{Text: "bar"}),
+			str:   "foo bar",
+		},
+	} {
+		t.Run(test.query, func(t *testing.T) {
+			got, err := postquery.Parse(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %#v, want %#v", got, test.want)
+			}
+
+			str := test.str
+			if str == "" {
+				str = test.query
+			}
+			if got.String() != str {
+				t.Errorf("String() = %q, want %q", got.String(), str)
+			}
+			again, err := postquery.Parse(got.String())
+			if err != nil {
+				t.Fatalf("parse the rendered query: %s", err)
+			}
+			if !reflect.DeepEqual(again, got) {
+				t.Errorf("round trip: got %#v, want %#v", again, got)
+			}
+		})
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	for _, query := range []string{
+		"foo:bar",
+		"-foo:bar",
+		`foo:"a \"quoted\" value"`,
+		"foo:bar:baz",
+		"foo:",
+		"is:archived -is:archived",
+		`"foo:bar" foo:bar`,
+		"tag:go OR foo:bar OR is:archived desc:created_at",
+	} {
+		t.Run(query, func(t *testing.T) {
+			q, err := postquery.Parse(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := q.String(); got != query {
+				t.Errorf("String() = %q, want %q", got, query)
+			}
+		})
+	}
+}
+
+func TestRawValidate(t *testing.T) {
+	for _, test := range []struct {
+		text    string
+		wantErr bool
+	}{
+		{text: "foo:bar"},
+		{text: `foo:"a b"`},
+		{text: "", wantErr: true},
+		{text: "foo bar", wantErr: true},
+		{text: "-foo:bar", wantErr: true},
+		{text: "OR", wantErr: true},
+		{text: `foo:"a b`, wantErr: true},
+	} {
+		err := postquery.Raw{Text: test.text}.Validate()
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("Validate(%q) = %v, want error: %t", test.text, err, test.wantErr)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	for _, query := range []string{
+		`"not closed`,
+		"OR foo",
+		"foo OR",
+		"foo OR OR bar",
+		"foo OR asc:stars",
+		"asc:stars desc:likes",
+		"-asc:stars",
+		"asc:unknown",
+		"created_at:2019-13-01",
+		"created_at:2019-02-01~2019-01-01",
+		"is:unknown",
+		"missing:title",
+		`title:""`,
+	} {
+		t.Run(query, func(t *testing.T) {
+			if q, err := postquery.Parse(query); err == nil {
+				t.Errorf("got %q, want an error", q)
+			}
+		})
+	}
+}
+
+// TestParseWithServer checks that a rendered query gets the same posts as the
+// original query.
+func TestParseWithServer(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	server.AddPost(docbase.Post{Title: "Go runbook", Body: "b", Tags: []docbase.Tag{{Name: "go"}}})
+	server.AddPost(docbase.Post{Title: "Rust memo", Body: "b", Tags: []docbase.Tag{{Name: "rust"}}})
+	server.AddPost(docbase.Post{Title: "draft", Body: "b", Draft: true})
+	client := server.Client()
+	ctx := context.Background()
+
+	for _, query := range []string{
+		"tag:go OR tag:rust",
+		`title:"go runbook"`,
+		"-is:draft missing:tag",
+		"-tag:go asc:created_at",
+	} {
+		t.Run(query, func(t *testing.T) {
+			parsed, err := postquery.Parse(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, _, err := client.Post.List().Query(query).Do(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _, err := client.Post.List().Filter(parsed).Do(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %d posts, want %d posts", len(got), len(want))
+			}
+		})
+	}
+}
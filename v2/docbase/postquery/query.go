@@ -138,7 +138,7 @@ func Date(property DateName, year, month, day int) string {
 
 // DateRange specifies the date range with property name to search posts
 func DateRange(property DateName, year1, month1, day1, year2, month2, day2 int) string {
-	return fmt.Sprintf("%s:%02d-%02d-%02d~%2d-%02d-%02d", property, year1, month1, day1, year2, month2, day2)
+	return fmt.Sprintf("%s:%02d-%02d-%02d~%02d-%02d-%02d", property, year1, month1, day1, year2, month2, day2)
 }
 
 // Missing specifies a property to search posts which is not filled
@@ -0,0 +1,239 @@
+package postquery
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Term is an element of a Query, like a keyword or a property filter.
+type Term interface {
+	// String renders the term in the Docbase query syntax.
+	String() string
+	// Validate checks whether the term can be searched.
+	Validate() error
+
+	negate() Term
+}
+
+// Not negates the term (e.g. "-tag:draft").
+// Negating a negated term makes it positive again.
+func Not(term Term) Term {
+	return term.negate()
+}
+
+// dateLayout is the format of dates in queries.
+const dateLayout = "2006-01-02"
+
+// needsQuote checks whether the value has characters which have special
+// meaning in queries.
+func needsQuote(value string) bool {
+	if value == "" || value == "OR" || strings.HasPrefix(value, "-") {
+		return true
+	}
+	return strings.IndexFunc(value, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"' || r == ':'
+	}) >= 0
+}
+
+// quote quotes the value if it is necessary.
+func quote(value string) string {
+	if !needsQuote(value) {
+		return value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func sign(negated bool) string {
+	if negated {
+		return "-"
+	}
+	return ""
+}
+
+// Keyword searches posts which contain the text.
+type Keyword struct {
+	Text    string
+	Negated bool
+}
+
+func (t Keyword) String() string { return sign(t.Negated) + quote(t.Text) }
+
+// Validate implements Term.
+func (t Keyword) Validate() error {
+	if strings.TrimSpace(t.Text) == "" {
+		return errors.New("keyword is empty")
+	}
+	return nil
+}
+
+func (t Keyword) negate() Term { t.Negated = !t.Negated; return t }
+
+// Raw is a term which is sent as it is, like a filter by a property which
+// this package does not know (e.g. "foo:bar"). It cannot be evaluated locally.
+type Raw struct {
+	Text    string
+	Negated bool
+}
+
+func (t Raw) String() string { return sign(t.Negated) + t.Text }
+
+// Validate implements Term.
+func (t Raw) Validate() error {
+	tokens, err := tokenize(t.Text)
+	if err != nil {
+		return fmt.Errorf("invalid term %q: %w", t.Text, err)
+	}
+	if len(tokens) != 1 || tokens[0].negated || tokens[0].isOr() {
+		return fmt.Errorf("invalid term %q: it must be a single term", t.Text)
+	}
+	return nil
+}
+
+func (t Raw) negate() Term { t.Negated = !t.Negated; return t }
+
+// PropertyFilter searches posts whose property contains the value
+// (e.g. "title:foo", "tag:bar").
+type PropertyFilter struct {
+	Property PropertyName
+	Value    string
+	Negated  bool
+}
+
+func (t PropertyFilter) String() string {
+	return sign(t.Negated) + t.Property.String() + ":" + quote(t.Value)
+}
+
+// Validate implements Term.
+func (t PropertyFilter) Validate() error {
+	if !t.Property.valid() {
+		return fmt.Errorf("unknown property %q", t.Property)
+	}
+	if strings.TrimSpace(t.Value) == "" {
+		return fmt.Errorf("value for %s is empty", t.Property)
+	}
+	return nil
+}
+
+func (t PropertyFilter) negate() Term { t.Negated = !t.Negated; return t }
+
+// DateFilter searches posts whose date property is in the range.
+// The dates are compared by day in the location of each time.
+// Zero From or To means the range is open on the side.
+type DateFilter struct {
+	Property DateName
+	From     time.Time
+	To       time.Time
+	Negated  bool
+}
+
+func (t DateFilter) String() string {
+	from, to := "*", "*"
+	if !t.From.IsZero() {
+		from = t.From.Format(dateLayout)
+	}
+	if !t.To.IsZero() {
+		to = t.To.Format(dateLayout)
+	}
+	value := from + "~" + to
+	if from == to {
+		value = from
+	}
+	return sign(t.Negated) + t.Property.String() + ":" + value
+}
+
+// Validate implements Term.
+func (t DateFilter) Validate() error {
+	if !t.Property.valid() {
+		return fmt.Errorf("unknown date property %q", t.Property)
+	}
+	if t.From.IsZero() && t.To.IsZero() {
+		return fmt.Errorf("range for %s is empty", t.Property)
+	}
+	if !t.From.IsZero() && !t.To.IsZero() && t.From.Format(dateLayout) > t.To.Format(dateLayout) {
+		return fmt.Errorf("range for %s starts after its end", t.Property)
+	}
+	return nil
+}
+
+func (t DateFilter) negate() Term { t.Negated = !t.Negated; return t }
+
+// MissingFilter searches posts whose property is not filled (e.g. "missing:tag").
+type MissingFilter struct {
+	Property MissingName
+	Negated  bool
+}
+
+func (t MissingFilter) String() string {
+	return sign(t.Negated) + Missing(t.Property)
+}
+
+// Validate implements Term.
+func (t MissingFilter) Validate() error {
+	if t.Property != MissingNameTag {
+		return fmt.Errorf("unknown missing property %q", t.Property)
+	}
+	return nil
+}
+
+func (t MissingFilter) negate() Term { t.Negated = !t.Negated; return t }
+
+// StateFilter searches posts in the state (e.g. "is:draft", "has:star").
+type StateFilter struct {
+	State   StateName
+	Negated bool
+}
+
+func (t StateFilter) String() string { return sign(t.Negated) + t.State.String() }
+
+// Validate implements Term.
+func (t StateFilter) Validate() error {
+	switch t.State {
+	case StateDraft, StateArchived, StateUnread, StateShared, StateStarred:
+		return nil
+	}
+	return fmt.Errorf("unknown state %q", t.State)
+}
+
+func (t StateFilter) negate() Term { t.Negated = !t.Negated; return t }
+
+// StateName specifies a state of posts to search.
+type StateName string
+
+// Concrete states of posts to search.
+const (
+	StateDraft    = StateName("is:draft")
+	StateArchived = StateName("is:archived")
+	StateUnread   = StateName("is:unread")
+	StateShared   = StateName("is:shared")
+	StateStarred  = StateName("has:star")
+)
+
+func (s StateName) String() string {
+	return string(s)
+}
+
+func (s PropertyName) valid() bool {
+	switch s {
+	case PropertyNameTitle, PropertyNameBody, PropertyNameComments,
+		PropertyNameAttachments, PropertyNameAuthor, PropertyNameCommentedBy,
+		PropertyNameLikedBy, PropertyNameTag, PropertyNameGroup:
+		return true
+	}
+	return false
+}
+
+func (s DateName) valid() bool {
+	return s == DateNameCreatedAt || s == DateNameChangedAt
+}
+
+func (s SortName) valid() bool {
+	switch s {
+	case SortNameScore, SortNameChangedAt, SortNameCreatedAt,
+		SortNameStars, SortNameComments, SortNameLikes:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package postquery_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyoh86/go-docbase/v2/docbase/postquery"
+)
+
+// date parses the date, or gets the zero time for an empty string.
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+	if s == "" {
+		return time.Time{}
+	}
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestDateFilterString(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	for _, test := range []struct {
+		title  string
+		filter postquery.DateFilter
+		want   string
+	}{
+		{
+			title:  "range",
+			filter: postquery.DateFilter{Property: postquery.DateNameCreatedAt, From: date(t, "2019-01-01"), To: date(t, "2019-01-31")},
+			want:   "created_at:2019-01-01~2019-01-31",
+		},
+		{
+			title:  "single day",
+			filter: postquery.DateFilter{Property: postquery.DateNameCreatedAt, From: date(t, "2019-01-01"), To: date(t, "2019-01-01")},
+			want:   "created_at:2019-01-01",
+		},
+		{
+			title:  "open start",
+			filter: postquery.DateFilter{Property: postquery.DateNameChangedAt, To: date(t, "2019-01-31")},
+			want:   "changed_at:*~2019-01-31",
+		},
+		{
+			title:  "open end",
+			filter: postquery.DateFilter{Property: postquery.DateNameChangedAt, From: date(t, "2019-01-01")},
+			want:   "changed_at:2019-01-01~*",
+		},
+		{
+			title:  "negated",
+			filter: postquery.DateFilter{Property: postquery.DateNameCreatedAt, From: date(t, "2019-01-01"), Negated: true},
+			want:   "-created_at:2019-01-01~*",
+		},
+		{
+			title:  "times in a day",
+			filter: postquery.DateFilter{Property: postquery.DateNameCreatedAt, From: time.Date(2019, 1, 1, 3, 0, 0, 0, time.UTC), To: time.Date(2019, 1, 1, 21, 0, 0, 0, time.UTC)},
+			want:   "created_at:2019-01-01",
+		},
+		{
+			// The same instant is on the next day in JST.
+			title:  "location",
+			filter: postquery.DateFilter{Property: postquery.DateNameCreatedAt, From: time.Date(2019, 1, 1, 20, 0, 0, 0, time.UTC).In(jst)},
+			want:   "created_at:2019-01-02~*",
+		},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			if got := test.filter.String(); got != test.want {
+				t.Errorf("String() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
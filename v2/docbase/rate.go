@@ -1,9 +1,11 @@
 package docbase
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -64,3 +66,43 @@ func parseRate(r *http.Response) Rate {
 	}
 	return rate
 }
+
+// minRateLimitWait is the duration to wait for the rate limit when the reset
+// time is unknown or already passed.
+const minRateLimitWait = time.Second
+
+// waitRateLimit waits until the rate limit is reset.
+// If ctx is done or its deadline comes before the reset time, it returns an
+// error without waiting.
+func (c *Client) waitRateLimit(ctx context.Context, req *http.Request, rate Rate) error {
+	wait := time.Until(rate.Reset.Time)
+	if wait < minRateLimitWait {
+		wait = minRateLimitWait
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(time.Now().Add(wait)) {
+		return context.DeadlineExceeded
+	}
+	if c.OnRateLimitWait != nil {
+		c.OnRateLimitWait(req, rate, wait)
+	}
+
+	return sleep(ctx, wait)
+}
+
+// rateState holds the rate limit shared by clients with the same access token.
+type rateState struct {
+	mu   sync.Mutex
+	rate Rate
+}
+
+func (s *rateState) get() Rate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rate
+}
+
+func (s *rateState) set(rate Rate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rate = rate
+}
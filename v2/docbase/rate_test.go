@@ -0,0 +1,99 @@
+package docbase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+func TestWaitRateLimit(t *testing.T) {
+	for _, test := range []struct {
+		title string
+		// remaining is the rate limit remaining before the first request.
+		// If it is 1, the first request passes and the second one waits
+		// without being sent; if it is 0, the first one gets 429.
+		remaining    int64
+		calls        int
+		wantRequests int
+	}{
+		{title: "exceeded by the response", remaining: 0, calls: 1, wantRequests: 2},
+		{title: "exceeded before the request", remaining: 1, calls: 2, wantRequests: 2},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			server := docbasetest.NewServer()
+			defer server.Close()
+			reset := time.Now().Add(2 * time.Second)
+			server.SetRate(test.remaining, reset)
+			handler := &flakyHandler{server: server}
+			recorder := httptest.NewServer(handler)
+			defer recorder.Close()
+			client, err := docbase.NewAuthClient(server.Domain, server.Token).WithBaseURL(recorder.URL + "/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.WaitRateLimit = true
+			var waits []time.Duration
+			client.OnRateLimitWait = func(req *http.Request, rate docbase.Rate, wait time.Duration) {
+				if rate.Remaining != 0 {
+					t.Errorf("remaining = %d, want 0", rate.Remaining)
+				}
+				waits = append(waits, wait)
+			}
+			ctx := context.Background()
+
+			for i := 0; i < test.calls; i++ {
+				if _, _, err := client.Tag.List().Do(ctx); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if time.Now().Before(reset.Truncate(time.Second)) {
+				t.Errorf("returned at %s, want after the reset at %s", time.Now(), reset)
+			}
+			if len(waits) != 1 || waits[0] <= 0 || waits[0] > 2*time.Second {
+				t.Errorf("waits = %v, want one up to 2s", waits)
+			}
+			if got := handler.count(); got != test.wantRequests {
+				t.Errorf("%d requests are sent, want %d", got, test.wantRequests)
+			}
+		})
+	}
+}
+
+func TestWaitRateLimitDeadline(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	server.SetRate(0, time.Now().Add(time.Hour))
+	handler := &flakyHandler{server: server}
+	recorder := httptest.NewServer(handler)
+	defer recorder.Close()
+	client, err := docbase.NewAuthClient(server.Domain, server.Token).WithBaseURL(recorder.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.WaitRateLimit = true
+	waited := false
+	client.OnRateLimitWait = func(*http.Request, docbase.Rate, time.Duration) { waited = true }
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err = client.Tag.List().Do(ctx)
+	if !errors.Is(err, docbase.ErrRateLimited) {
+		t.Errorf("error = %v, want ErrRateLimited", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("returned after %s, want without waiting", elapsed)
+	}
+	if waited {
+		t.Error("OnRateLimitWait is called, want not to wait")
+	}
+	if got := handler.count(); got != 1 {
+		t.Errorf("%d requests are sent, want 1", got)
+	}
+}
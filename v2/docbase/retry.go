@@ -0,0 +1,166 @@
+package docbase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy specifies how Client.Do retries a request which failed
+// transiently (e.g. 502, 503, 504 or a connection reset).
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	// If it is less than 2, requests will not be retried.
+	MaxAttempts int
+
+	// MinBackoff is the duration to wait before the first retry.
+	// It is doubled for each following retry.
+	MinBackoff time.Duration
+
+	// MaxBackoff is the maximum duration to wait before a retry.
+	// If it is zero, the duration is not capped.
+	MaxBackoff time.Duration
+
+	// Jitter is the ratio (from 0 to 1) of the backoff to be randomized,
+	// to keep retries from several clients from being synchronized.
+	Jitter float64
+
+	// RetryableStatuses are status codes of responses to be retried.
+	// If it is nil, DefaultRetryableStatuses will be used.
+	RetryableStatuses []int
+
+	// RetryableError reports whether an error from the transport can be
+	// retried. If it is nil, connection resets, refusals, unexpected EOFs and
+	// timeouts are retried.
+	RetryableError func(err error) bool
+
+	// RetryNonIdempotent allows retrying non-idempotent requests (e.g. POST to
+	// create a post) even if the server may have processed them already.
+	// Without it, such requests are retried only when they were not sent.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryableStatuses are status codes to be retried by default.
+var DefaultRetryableStatuses = []int{
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryPolicy returns a RetryPolicy which retries up to 3 times with
+// exponential backoff from 1s to 30s.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		MinBackoff:  time.Second,
+		MaxBackoff:  30 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+// shouldRetry reports whether the request which got resp or err in the
+// attempts should be retried.
+func (p *RetryPolicy) shouldRetry(req *http.Request, resp *Response, err error, attempts int) bool {
+	if p == nil || err == nil || attempts >= p.MaxAttempts {
+		return false
+	}
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if resp != nil && resp.Response != nil {
+		statuses := p.RetryableStatuses
+		if statuses == nil {
+			statuses = DefaultRetryableStatuses
+		}
+		if !containsStatus(statuses, resp.StatusCode) {
+			return false
+		}
+		return p.RetryNonIdempotent || isIdempotent(req.Method)
+	}
+
+	// The error came from the transport.
+	retryable := p.RetryableError
+	if retryable == nil {
+		retryable = isTransientError
+	}
+	if !retryable(err) {
+		return false
+	}
+	return p.RetryNonIdempotent || isIdempotent(req.Method) || isNotSent(err)
+}
+
+// backoff calculates a duration to wait before the next attempt.
+func (p *RetryPolicy) backoff(attempts int) time.Duration {
+	wait := p.MinBackoff
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		delta := float64(wait) * p.Jitter
+		wait = time.Duration(float64(wait) - delta + 2*delta*rand.Float64())
+	}
+	return wait
+}
+
+// containsStatus reports whether the status code is in the statuses.
+func containsStatus(statuses []int, code int) bool {
+	for _, status := range statuses {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// isIdempotent reports whether the method is idempotent in RFC 7231.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isTransientError reports whether the error from the transport seems to be
+// solved by retrying.
+func isTransientError(err error) bool {
+	if errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isNotSent reports whether the error occurred before the request was sent.
+func isNotSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// sleep waits for the duration, or returns ctx.Err() if ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
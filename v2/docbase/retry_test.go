@@ -0,0 +1,185 @@
+package docbase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+// flakyHandler fails the first requests, and passes the others to the fake
+// server.
+type flakyHandler struct {
+	server *docbasetest.Server
+	// status is a status for failed requests. If it is zero, the connection
+	// is closed without a response.
+	status int
+	fails  int
+
+	mu       sync.Mutex
+	requests int
+}
+
+func (h *flakyHandler) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.requests
+}
+
+func (h *flakyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.requests++
+	fail := h.requests <= h.fails
+	h.mu.Unlock()
+	if !fail {
+		h.server.ServeHTTP(w, r)
+		return
+	}
+	if h.status != 0 {
+		http.Error(w, "unavailable", h.status)
+		return
+	}
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		panic(err)
+	}
+	conn.Close()
+}
+
+func TestRetryPolicy(t *testing.T) {
+	for _, test := range []struct {
+		title         string
+		method        string
+		status        int
+		fails         int
+		maxAttempts   int
+		nonIdempotent bool
+		wantErr       bool
+		wantRequests  int
+	}{
+		{title: "recovered", method: "GET", status: 503, fails: 2, maxAttempts: 4, wantRequests: 3},
+		{title: "gave up", method: "GET", status: 502, fails: 5, maxAttempts: 3, wantErr: true, wantRequests: 3},
+		{title: "connection closed", method: "GET", fails: 1, maxAttempts: 2, wantRequests: 2},
+		{title: "not retryable status", method: "GET", status: 500, fails: 1, maxAttempts: 4, wantErr: true, wantRequests: 1},
+		{title: "no policy", method: "GET", status: 503, fails: 1, maxAttempts: 0, wantErr: true, wantRequests: 1},
+		{title: "non-idempotent", method: "POST", status: 503, fails: 1, maxAttempts: 4, wantErr: true, wantRequests: 1},
+		{title: "non-idempotent closed", method: "POST", fails: 1, maxAttempts: 4, wantErr: true, wantRequests: 1},
+		{title: "non-idempotent allowed", method: "POST", status: 503, fails: 1, maxAttempts: 4, nonIdempotent: true, wantRequests: 2},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			server := docbasetest.NewServer()
+			defer server.Close()
+			handler := &flakyHandler{server: server, status: test.status, fails: test.fails}
+			flaky := httptest.NewServer(handler)
+			defer flaky.Close()
+
+			client, err := docbase.NewAuthClient(server.Domain, server.Token).WithBaseURL(flaky.URL + "/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.maxAttempts > 0 {
+				client.RetryPolicy = &docbase.RetryPolicy{
+					MaxAttempts:        test.maxAttempts,
+					MinBackoff:         time.Millisecond,
+					RetryNonIdempotent: test.nonIdempotent,
+				}
+			}
+
+			ctx := context.Background()
+			var resp *docbase.Response
+			if test.method == "POST" {
+				_, resp, err = client.Post.Create("title", "body").Do(ctx)
+			} else {
+				_, resp, err = client.Tag.List().Do(ctx)
+			}
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("error = %v, want error: %t", err, test.wantErr)
+			}
+			if got := handler.count(); got != test.wantRequests {
+				t.Errorf("requests = %d, want %d", got, test.wantRequests)
+			}
+			if resp != nil && test.status != 0 && resp.Attempts != test.wantRequests {
+				t.Errorf("attempts = %d, want %d", resp.Attempts, test.wantRequests)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBodyRewound(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	handler := &flakyHandler{server: server, status: 503, fails: 2}
+	flaky := httptest.NewServer(handler)
+	defer flaky.Close()
+	client, err := docbase.NewAuthClient(server.Domain, server.Token).WithBaseURL(flaky.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RetryPolicy = &docbase.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, RetryNonIdempotent: true}
+
+	post, _, err := client.Post.Create("title", "body").Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Title != "title" || post.Body != "body" {
+		t.Errorf("post = %q/%q, want title/body", post.Title, post.Body)
+	}
+}
+
+func TestRetryPolicyCanceled(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	handler := &flakyHandler{server: server, status: 503, fails: 10}
+	flaky := httptest.NewServer(handler)
+	defer flaky.Close()
+	client, err := docbase.NewAuthClient(server.Domain, server.Token).WithBaseURL(flaky.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RetryPolicy = &docbase.RetryPolicy{MaxAttempts: 10, MinBackoff: time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, _, err := client.Tag.List().Do(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want context.DeadlineExceeded", err)
+	}
+	if got := handler.count(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	for _, test := range []struct {
+		title    string
+		policy   docbase.RetryPolicy
+		attempts int
+		want     time.Duration
+	}{
+		{title: "first", policy: docbase.RetryPolicy{MinBackoff: time.Second}, attempts: 1, want: time.Second},
+		{title: "doubled", policy: docbase.RetryPolicy{MinBackoff: time.Second}, attempts: 3, want: 4 * time.Second},
+		{title: "capped", policy: docbase.RetryPolicy{MinBackoff: time.Second, MaxBackoff: 5 * time.Second}, attempts: 4, want: 5 * time.Second},
+		{title: "not overflowed", policy: docbase.RetryPolicy{MinBackoff: time.Second, MaxBackoff: time.Minute}, attempts: 100, want: time.Minute},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			if got := docbase.Backoff(&test.policy, test.attempts); got != test.want {
+				t.Errorf("backoff = %s, want %s", got, test.want)
+			}
+		})
+	}
+
+	t.Run("jitter", func(t *testing.T) {
+		policy := docbase.RetryPolicy{MinBackoff: time.Second, Jitter: 0.2}
+		for i := 0; i < 100; i++ {
+			got := docbase.Backoff(&policy, 1)
+			if got < 800*time.Millisecond || got > 1200*time.Millisecond {
+				t.Fatalf("backoff = %s, want in 0.8s..1.2s", got)
+			}
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package docbase
+
+import (
+	"context"
+)
+
+// teamService provides access to the team related functions
+// in the Docbase API.
+type teamService service
+
+// List teams which the user of the access token belongs to.
+// Use Client.ForTeam to get a client for each of them.
+//
+// Docbase API docs: https://help.docbase.io/posts/92977
+func (s *teamService) List() *teamListDoer {
+	return &teamListDoer{client: s.client}
+}
+
+type teamListDoer struct {
+	client *Client
+}
+
+func (d *teamListDoer) Do(ctx context.Context) ([]Team, *Response, error) {
+	req, err := d.client.newRequest("GET", "teams", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var teams []Team
+	resp, err := d.client.Do(ctx, req, &teams)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return teams, resp, nil
+}
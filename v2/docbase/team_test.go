@@ -0,0 +1,78 @@
+package docbase_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+func TestTeamList(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	handler := &pathRecorder{server: server}
+	recorder := httptest.NewServer(handler)
+	defer recorder.Close()
+	client, err := docbase.NewAuthClient(server.Domain, server.Token).WithBaseURL(recorder.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	teams, _, err := client.Team.List().Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []docbase.Team{{Domain: server.Domain, Name: server.Domain}}
+	if len(teams) != len(want) || teams[0] != want[0] {
+		t.Errorf("teams = %+v, want %+v", teams, want)
+	}
+	if requests := handler.recorded(); !equalStrings(requests, []string{"GET /teams?"}) {
+		t.Errorf("requests = %q, want only GET /teams", requests)
+	}
+}
+
+func TestForTeam(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	handler := &pathRecorder{server: server}
+	recorder := httptest.NewServer(handler)
+	defer recorder.Close()
+	parent, err := docbase.NewAuthClient(server.Domain, server.Token).WithBaseURL(recorder.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	other := parent.ForTeam("other")
+	if other.Domain() != "other" || parent.Domain() != server.Domain {
+		t.Errorf("domains = %q, %q, want other, %q", other.Domain(), parent.Domain(), server.Domain)
+	}
+	other.WaitRateLimit = true
+	if parent.WaitRateLimit {
+		t.Error("WaitRateLimit of the parent is changed by the team client")
+	}
+	if _, _, err := other.Tag.List().Do(ctx); !errors.Is(err, docbase.ErrNotFound) {
+		t.Errorf("error = %v, want not found for the unknown team", err)
+	}
+	if requests := handler.recorded(); !equalStrings(requests, []string{"GET /teams/other/tags?"}) {
+		t.Errorf("requests = %q, want to the other team", requests)
+	}
+
+	// The rate limit exceeded by the team client stops the parent too.
+	server.SetRate(1, time.Now().Add(time.Hour))
+	if _, _, err := other.ForTeam(server.Domain).Tag.List().Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	before := len(handler.recorded())
+	var rateErr *docbase.RateLimitError
+	if _, _, err := parent.Tag.List().Do(ctx); !errors.As(err, &rateErr) {
+		t.Errorf("error = %v, want *docbase.RateLimitError", err)
+	}
+	if after := len(handler.recorded()); after != before {
+		t.Errorf("%d requests are sent, want none after the rate limit is exceeded", after-before)
+	}
+}
@@ -0,0 +1,178 @@
+package docbase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+const (
+	defaultMaxFileSize   = 100 << 20
+	defaultMaxBatchSize  = 100 << 20
+	defaultMaxBatchFiles = 20
+)
+
+// ErrAttachmentTooLarge is returned when an attachment to upload exceeds the
+// size limit.
+var ErrAttachmentTooLarge = errors.New("attachment is too large")
+
+// errBodyReopened aborts encoding a request body which is replaced to send the
+// request again.
+var errBodyReopened = errors.New("request body is reopened")
+
+// uploadPayload is an attachment to upload.
+type uploadPayload struct {
+	name   string
+	reader io.Reader
+	size   int64 // -1 if it is unknown
+	offset int64 // the offset to read again from; -1 if the reader cannot seek
+}
+
+func newUploadPayload(name string, r io.Reader) *uploadPayload {
+	p := &uploadPayload{name: name, reader: r, size: -1, offset: -1}
+	if s, ok := r.(io.Seeker); ok {
+		if cur, err := s.Seek(0, io.SeekCurrent); err == nil {
+			if end, err := s.Seek(0, io.SeekEnd); err == nil {
+				if _, err := s.Seek(cur, io.SeekStart); err == nil {
+					p.size, p.offset = end-cur, cur
+					return p
+				}
+			}
+		}
+	}
+	if l, ok := r.(interface{ Len() int }); ok {
+		p.size = int64(l.Len())
+	}
+	return p
+}
+
+// header is a part of the JSON which precedes the content.
+func (p *uploadPayload) header() string {
+	name, _ := json.Marshal(p.name)
+	return `{"name":` + string(name) + `,"content":"`
+}
+
+const uploadPayloadTrailer = `"}`
+
+// uploadBody encodes payloads into a JSON array of attachments, like
+// [{"name":"...","content":"<base64>"}], streaming through an io.Pipe.
+type uploadBody struct {
+	payloads    []*uploadPayload
+	maxFileSize int64
+
+	mu   sync.Mutex
+	pr   *io.PipeReader
+	done chan struct{} // closed when the current encoding ends
+}
+
+// length calculates the length of the encoded body, or returns -1 if it is
+// unknown.
+func (b *uploadBody) length() int64 {
+	length := int64(len("[]"))
+	for i, p := range b.payloads {
+		if p.size < 0 {
+			return -1
+		}
+		if i > 0 {
+			length += int64(len(","))
+		}
+		length += int64(len(p.header())) + int64(base64.StdEncoding.EncodedLen(int(p.size))) + int64(len(uploadPayloadTrailer))
+	}
+	return length
+}
+
+// rewindable reports whether the body can be opened again.
+func (b *uploadBody) rewindable() bool {
+	for _, p := range b.payloads {
+		if p.offset < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// open starts encoding the payloads and returns a reader of the body.
+// Opening it again aborts the previous encoding and rewinds the payloads.
+func (b *uploadBody) open() (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done != nil {
+		b.stop()
+		for _, p := range b.payloads {
+			if _, err := p.reader.(io.Seeker).Seek(p.offset, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+	}
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pw.CloseWithError(b.encode(pw))
+	}()
+	b.pr, b.done = pr, done
+	return pr, nil
+}
+
+// close aborts the current encoding if it is not finished.
+func (b *uploadBody) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done != nil {
+		b.stop()
+	}
+}
+
+func (b *uploadBody) stop() {
+	b.pr.CloseWithError(errBodyReopened)
+	<-b.done
+}
+
+func (b *uploadBody) encode(w io.Writer) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i, p := range b.payloads {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, p.header()); err != nil {
+			return err
+		}
+		enc := base64.NewEncoder(base64.StdEncoding, w)
+		if err := p.copy(enc, b.maxFileSize); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, uploadPayloadTrailer); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]")
+	return err
+}
+
+// copy copies the content to w, checking its size.
+func (p *uploadPayload) copy(w io.Writer, maxFileSize int64) error {
+	if p.size >= 0 {
+		if _, err := io.CopyN(w, p.reader, p.size); err != nil {
+			return fmt.Errorf("read %q: %w", p.name, err)
+		}
+		return nil
+	}
+	n, err := io.Copy(w, io.LimitReader(p.reader, maxFileSize+1))
+	if err != nil {
+		return fmt.Errorf("read %q: %w", p.name, err)
+	}
+	if n > maxFileSize {
+		return fmt.Errorf("%w: %q has more than %d bytes", ErrAttachmentTooLarge, p.name, maxFileSize)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package docbase_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+)
+
+// uploadRecorder records the attachments uploaded in each request, and passes
+// the requests to the fake server.
+type uploadRecorder struct {
+	server *docbasetest.Server
+
+	mu       sync.Mutex
+	batches  [][]string // names of attachments in each request
+	lengths  []int64    // Content-Length of each request
+	invalids []error    // errors of bodies which are not valid JSON
+}
+
+func (h *uploadRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/attachments") {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			panic(err)
+		}
+		var payloads []struct {
+			Name string `json:"name"`
+		}
+		err = json.Unmarshal(body, &payloads)
+		var names []string
+		for _, p := range payloads {
+			names = append(names, p.Name)
+		}
+		h.mu.Lock()
+		h.batches = append(h.batches, names)
+		h.lengths = append(h.lengths, r.ContentLength)
+		if err != nil {
+			h.invalids = append(h.invalids, err)
+		}
+		h.mu.Unlock()
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	h.server.ServeHTTP(w, r)
+}
+
+func (h *uploadRecorder) recorded() (batches [][]string, lengths []int64, invalids []error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.batches, h.lengths, h.invalids
+}
+
+// onlyReader hides methods other than Read (e.g. Seek and Len), to make the
+// size of the content unknown.
+type onlyReader struct {
+	io.Reader
+}
+
+type uploadFile struct {
+	name    string
+	content string
+	stream  bool // whether it is added as a reader whose size is unknown
+}
+
+func TestAttachmentUpload(t *testing.T) {
+	for _, test := range []struct {
+		title         string
+		files         []uploadFile
+		maxFileSize   int64
+		maxBatchSize  int64
+		maxBatchFiles int
+		wantBatches   [][]string
+		wantLengths   []bool // whether Content-Length is known in each request
+		wantErr       error
+	}{
+		{
+			title:       "single",
+			files:       []uploadFile{{name: "a.txt", content: "hello"}},
+			wantBatches: [][]string{{"a.txt"}},
+			wantLengths: []bool{true},
+		},
+		{
+			title: "padding",
+			files: []uploadFile{
+				{name: "a.txt", content: ""},
+				{name: "b.txt", content: "1"},
+				{name: "c.txt", content: "12"},
+				{name: "d.txt", content: "123"},
+				{name: `"quoted".txt`, content: "\x00\xff binary"},
+			},
+			wantBatches: [][]string{{"a.txt", "b.txt", "c.txt", "d.txt", `"quoted".txt`}},
+			wantLengths: []bool{true},
+		},
+		{
+			title: "split by files",
+			files: []uploadFile{
+				{name: "a.txt", content: "a"},
+				{name: "b.txt", content: "b"},
+				{name: "c.txt", content: "c"},
+			},
+			maxBatchFiles: 2,
+			wantBatches:   [][]string{{"a.txt", "b.txt"}, {"c.txt"}},
+			wantLengths:   []bool{true, true},
+		},
+		{
+			title: "split by size",
+			files: []uploadFile{
+				{name: "a.txt", content: "aaaa"},
+				{name: "b.txt", content: "bbbb"},
+				{name: "c.txt", content: "cccccccccc"},
+				{name: "d.txt", content: "d"},
+			},
+			maxBatchSize: 8,
+			wantBatches:  [][]string{{"a.txt", "b.txt"}, {"c.txt"}, {"d.txt"}},
+			wantLengths:  []bool{true, true, true},
+		},
+		{
+			title: "stream",
+			files: []uploadFile{
+				{name: "a.txt", content: strings.Repeat("streamed ", 10000), stream: true},
+				{name: "b.txt", content: "b"},
+			},
+			maxFileSize:  100000,
+			maxBatchSize: 100000,
+			wantBatches:  [][]string{{"a.txt"}, {"b.txt"}},
+			wantLengths:  []bool{false, true},
+		},
+		{
+			title:       "too large",
+			files:       []uploadFile{{name: "a.txt", content: "123456"}},
+			maxFileSize: 5,
+			wantErr:     docbase.ErrAttachmentTooLarge,
+		},
+		{
+			title:       "stream too large",
+			files:       []uploadFile{{name: "a.txt", content: "123456", stream: true}},
+			maxFileSize: 5,
+			wantErr:     docbase.ErrAttachmentTooLarge,
+		},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			server := docbasetest.NewServer()
+			defer server.Close()
+			handler := &uploadRecorder{server: server}
+			recorder := httptest.NewServer(handler)
+			defer recorder.Close()
+			client, err := docbase.NewAuthClient(server.Domain, server.Token).WithBaseURL(recorder.URL + "/")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			doer := client.Attachment.Upload()
+			if test.maxFileSize > 0 {
+				doer.MaxFileSize(test.maxFileSize)
+			}
+			if test.maxBatchSize > 0 {
+				doer.MaxBatchSize(test.maxBatchSize)
+			}
+			if test.maxBatchFiles > 0 {
+				doer.MaxBatchFiles(test.maxBatchFiles)
+			}
+			for _, f := range test.files {
+				if f.stream {
+					doer.AddReader(f.name, onlyReader{strings.NewReader(f.content)})
+				} else {
+					doer.AddPayload(f.name, []byte(f.content))
+				}
+			}
+
+			attachments, _, err := doer.Do(context.Background())
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("error = %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			batches, lengths, invalids := handler.recorded()
+			if len(invalids) > 0 {
+				t.Errorf("invalid bodies: %v", invalids)
+			}
+			if len(batches) != len(test.wantBatches) {
+				t.Fatalf("batches = %q, want %q", batches, test.wantBatches)
+			}
+			for i, want := range test.wantBatches {
+				if !equalStrings(batches[i], want) {
+					t.Errorf("batch %d = %q, want %q", i, batches[i], want)
+				}
+				if got := lengths[i] >= 0; got != test.wantLengths[i] {
+					t.Errorf("batch %d: Content-Length = %d, want known: %t", i, lengths[i], test.wantLengths[i])
+				}
+			}
+
+			if len(attachments) != len(test.files) {
+				t.Fatalf("attachments = %d, want %d", len(attachments), len(test.files))
+			}
+			for i, f := range test.files {
+				a, content, ok := server.Attachment(attachments[i].ID)
+				if !ok {
+					t.Fatalf("attachment %q is not uploaded", f.name)
+				}
+				if a.Name != f.name || string(content) != f.content {
+					t.Errorf("attachment %d = %q (%d bytes), want %q (%d bytes)", i, a.Name, len(content), f.name, len(f.content))
+				}
+			}
+		})
+	}
+}
+
+func TestAttachmentUploadRetried(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	handler := &flakyHandler{server: server, status: 503, fails: 1}
+	flaky := httptest.NewServer(handler)
+	defer flaky.Close()
+	client, err := docbase.NewAuthClient(server.Domain, server.Token).WithBaseURL(flaky.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RetryPolicy = &docbase.RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond, RetryNonIdempotent: true}
+
+	reader := strings.NewReader("skipped content")
+	if _, err := reader.Seek(int64(len("skipped ")), io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	attachments, _, err := client.Attachment.Upload().AddReader("a.txt", reader).Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := handler.count(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+	if _, content, _ := server.Attachment(attachments[0].ID); string(content) != "content" {
+		t.Errorf("content = %q, want %q", content, "content")
+	}
+}
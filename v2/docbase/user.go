@@ -29,6 +29,7 @@ type userListOptions struct {
 type userListDoer struct {
 	opts   userListOptions
 	client *Client
+	limit  int64
 }
 
 func (d *userListDoer) Query(query string) *userListDoer {
@@ -70,3 +71,54 @@ func (d *userListDoer) Do(ctx context.Context) ([]User, *Response, error) {
 
 	return users, resp, nil
 }
+
+// Limit sets the maximum number of users to get with Each or All.
+func (d *userListDoer) Limit(limit int64) *userListDoer {
+	d.limit = limit
+	return d
+}
+
+// Each calls f for each user, walking through pages from the current page
+// until all users are visited or the limit is reached.
+// If f returns an error, Each stops and returns it.
+func (d *userListDoer) Each(ctx context.Context, f func(User) error) error {
+	doer := *d
+	if doer.opts.PerPage == nil {
+		perPage := int64(defaultWalkPerPage)
+		doer.opts.PerPage = &perPage
+	}
+	var count int64
+	var pageSize int
+	return walkPages(ctx, &doer.opts.ListOptions, func(ctx context.Context) (*ListOptions, error) {
+		users, _, err := doer.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			if limitReached(d.limit, count) {
+				return nil, nil
+			}
+			count++
+			if err := f(user); err != nil {
+				return nil, err
+			}
+		}
+		if limitReached(d.limit, count) {
+			return nil, nil
+		}
+		return guessNextPage(doer.opts.ListOptions, len(users), &pageSize), nil
+	})
+}
+
+// All gets all users, walking through pages from the current page
+// until all users are got or the limit is reached.
+func (d *userListDoer) All(ctx context.Context) ([]User, error) {
+	var users []User
+	if err := d.Each(ctx, func(user User) error {
+		users = append(users, user)
+		return nil
+	}); err != nil {
+		return users, err
+	}
+	return users, nil
+}
@@ -1,5 +1,8 @@
 module github.com/kyoh86/go-docbase/v2
 
-require github.com/google/go-querystring v1.0.0
+require (
+	github.com/google/go-querystring v1.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 go 1.13
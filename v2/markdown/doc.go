@@ -0,0 +1,5 @@
+/*
+Package markdown converts Docbase posts to and from Markdown files with YAML
+front matter.
+*/
+package markdown
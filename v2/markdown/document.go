@@ -0,0 +1,169 @@
+package markdown
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"gopkg.in/yaml.v3"
+)
+
+// delimiter encloses front matter.
+const delimiter = "---"
+
+// FrontMatter holds properties of a post in a Markdown document.
+// Tags and Draft are nil if they are not in front matter, so that importing
+// the document keeps them of the post as they are.
+type FrontMatter struct {
+	ID            docbase.PostID `yaml:"id,omitempty"`
+	Title         string         `yaml:"title"`
+	Tags          *[]string      `yaml:"tags,omitempty"`
+	Groups        []string       `yaml:"groups,omitempty"`
+	Scope         docbase.Scope  `yaml:"scope,omitempty"`
+	Draft         *bool          `yaml:"draft,omitempty"`
+	Author        string         `yaml:"author,omitempty"`
+	CreatedAt     string         `yaml:"created_at,omitempty"`
+	URL           string         `yaml:"url,omitempty"`
+	StarsCount    int64          `yaml:"stars_count"`
+	GoodJobsCount int64          `yaml:"good_jobs_count"`
+
+	// Parameters only for creating or editing a post.
+	Notice *bool `yaml:"notice,omitempty"`
+
+	// Parameters only for creating a post, that only owners can use.
+	AuthorID    docbase.UserID `yaml:"author_id,omitempty"`
+	PublishedAt *time.Time     `yaml:"published_at,omitempty"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, to tell which field is invalid
+// if published_at is not a time.
+func (fm *FrontMatter) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			if value.Content[i].Value != "published_at" {
+				continue
+			}
+			var at *time.Time
+			if err := value.Content[i+1].Decode(&at); err != nil {
+				return fmt.Errorf("invalid published_at %q: it must be in RFC 3339 (e.g. 2019-01-31T10:00:00+09:00)", value.Content[i+1].Value)
+			}
+		}
+	}
+	type plain FrontMatter
+	return value.Decode((*plain)(fm))
+}
+
+// Document is a Markdown document with YAML front matter.
+type Document struct {
+	FrontMatter FrontMatter
+	Body        string
+}
+
+// NewDocument creates a Document from a post.
+func NewDocument(post *docbase.Post) *Document {
+	fm := FrontMatter{
+		ID:            post.ID,
+		Title:         post.Title,
+		Scope:         post.Scope,
+		Author:        post.User.Username,
+		CreatedAt:     post.CreatedAt,
+		URL:           post.URL,
+		StarsCount:    post.StarsCount,
+		GoodJobsCount: post.GoodJobsCount,
+	}
+	if post.Draft {
+		draft := true
+		fm.Draft = &draft
+	}
+	if len(post.Tags) > 0 {
+		tags := make([]string, 0, len(post.Tags))
+		for _, tag := range post.Tags {
+			tags = append(tags, tag.Name)
+		}
+		fm.Tags = &tags
+	}
+	for _, group := range post.Groups {
+		fm.Groups = append(fm.Groups, group.Name)
+	}
+	return &Document{FrontMatter: fm, Body: post.Body}
+}
+
+// ReadDocument reads a Markdown document.
+// If it does not start with front matter, the whole content is the body.
+// A newline at the end is removed from the body, as WriteTo adds it.
+func ReadDocument(r io.Reader) (*Document, error) {
+	br := bufio.NewReader(r)
+	first, err := br.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	doc := new(Document)
+	if strings.TrimRight(first, "\r\n") != delimiter {
+		rest, err := ioutil.ReadAll(br)
+		if err != nil {
+			return nil, err
+		}
+		doc.Body = trimNewline(first + string(rest))
+		return doc, nil
+	}
+
+	var header bytes.Buffer
+	for {
+		line, err := br.ReadString('\n')
+		if strings.TrimRight(line, "\r\n") == delimiter {
+			break
+		}
+		if err == io.EOF {
+			return nil, errors.New("front matter is not closed")
+		}
+		if err != nil {
+			return nil, err
+		}
+		header.WriteString(line)
+	}
+	if err := yaml.Unmarshal(header.Bytes(), &doc.FrontMatter); err != nil {
+		return nil, err
+	}
+	rest, err := ioutil.ReadAll(br)
+	if err != nil {
+		return nil, err
+	}
+	// A blank line separates front matter and the body.
+	body := strings.TrimPrefix(string(rest), "\n")
+	doc.Body = trimNewline(strings.TrimPrefix(body, "\r\n"))
+	return doc, nil
+}
+
+// trimNewline removes a newline at the end of the body.
+func trimNewline(body string) string {
+	if strings.HasSuffix(body, "\r\n") {
+		return body[:len(body)-2]
+	}
+	return strings.TrimSuffix(body, "\n")
+}
+
+// WriteTo writes the document with front matter.
+// A newline is always added after the body to end the file, and ReadDocument
+// removes it, so that the body is read back as it is.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	buf.WriteString(delimiter + "\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(d.FrontMatter); err != nil {
+		return 0, err
+	}
+	if err := enc.Close(); err != nil {
+		return 0, err
+	}
+	buf.WriteString(delimiter + "\n\n")
+	buf.WriteString(d.Body)
+	buf.WriteString("\n")
+	return buf.WriteTo(w)
+}
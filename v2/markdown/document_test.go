@@ -0,0 +1,160 @@
+package markdown_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+	"github.com/kyoh86/go-docbase/v2/markdown"
+)
+
+func TestDocumentRoundTrip(t *testing.T) {
+	for _, test := range []struct {
+		title string
+		body  string
+	}{
+		{title: "empty", body: ""},
+		{title: "no newline", body: "line"},
+		{title: "newline", body: "line\n"},
+		{title: "newlines", body: "line\n\n"},
+		{title: "blank lines first", body: "\n\nline"},
+		{title: "delimiter in the body", body: "a\n---\nb"},
+		{title: "CRLF", body: "a\r\nb\r\n"},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			doc := &markdown.Document{
+				FrontMatter: markdown.FrontMatter{ID: 1, Title: "title", Tags: &[]string{"a", "b"}},
+				Body:        test.body,
+			}
+			var buf bytes.Buffer
+			if _, err := doc.WriteTo(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(buf.String(), "\n") {
+				t.Errorf("the file does not end with a newline: %q", buf.String())
+			}
+			got, err := markdown.ReadDocument(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Body != test.body {
+				t.Errorf("body = %q, want %q", got.Body, test.body)
+			}
+			if got.FrontMatter.Title != "title" || got.FrontMatter.Tags == nil || strings.Join(*got.FrontMatter.Tags, ",") != "a,b" {
+				t.Errorf("front matter = %+v", got.FrontMatter)
+			}
+		})
+	}
+}
+
+func TestReadDocument(t *testing.T) {
+	for _, test := range []struct {
+		title   string
+		content string
+		want    string // the title in front matter and the body
+		wantErr bool
+	}{
+		{title: "no front matter", content: "# heading\nbody\n", want: "|# heading\nbody"},
+		{title: "front matter", content: "---\ntitle: t\n---\n\nbody\n", want: "t|body"},
+		{title: "no blank line", content: "---\ntitle: t\n---\nbody\n", want: "t|body"},
+		{title: "CRLF", content: "---\r\ntitle: t\r\n---\r\n\r\nbody\r\n", want: "t|body"},
+		{title: "empty body", content: "---\ntitle: t\n---\n", want: "t|"},
+		{title: "not closed", content: "---\ntitle: t\nbody\n", wantErr: true},
+		{title: "invalid YAML", content: "---\ntitle: [\n---\n", wantErr: true},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			doc, err := markdown.ReadDocument(strings.NewReader(test.content))
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("got %+v, want an error", doc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := doc.FrontMatter.Title + "|" + doc.Body; got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// TestExportImport checks that importing exported files does not change posts.
+func TestExportImport(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	group := server.AddGroup(docbase.Group{Name: "dev"})
+	bodies := []string{"no newline", "newline\n", "newlines\n\n"}
+	for _, body := range bodies {
+		server.AddPost(docbase.Post{
+			Title:  body,
+			Body:   body,
+			Scope:  docbase.ScopeGroup,
+			Groups: []docbase.Group{{ID: group.ID, Name: group.Name}},
+			Tags:   []docbase.Tag{{Name: "go"}},
+			Draft:  true,
+		})
+	}
+	client := server.Client()
+	ctx := context.Background()
+
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	paths, err := markdown.Export(ctx, client, dir, markdown.ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != len(bodies) {
+		t.Fatalf("exported %d files, want %d", len(paths), len(bodies))
+	}
+	before, err := client.Post.List().All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := markdown.Import(ctx, client, dir, markdown.ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if result.Err != nil || result.Action != markdown.ImportActionUpdated {
+			t.Errorf("%s: %s %v", result.Path, result.Action, result.Err)
+		}
+	}
+	after, err := client.Post.List().All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range before {
+		b, a := before[i], after[i]
+		if a.Body != b.Body || a.Title != b.Title || a.Draft != b.Draft || len(a.Tags) != len(b.Tags) || len(a.Groups) != len(b.Groups) {
+			t.Errorf("post %d is changed: %+v -> %+v", b.ID, b, a)
+		}
+	}
+
+	// Exporting again writes the same files.
+	again, cleanupAgain := tempDir(t)
+	defer cleanupAgain()
+	if _, err := markdown.Export(ctx, client, again, markdown.ExportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		want, err := ioutil.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadFile(filepath.Join(again, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s is changed:\n%s\n->\n%s", path, want, got)
+		}
+	}
+}
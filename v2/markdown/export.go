@@ -0,0 +1,153 @@
+package markdown
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+// commentsMarker separates comments appended to the body by Export.
+const commentsMarker = "<!-- docbase:comments -->"
+
+// maxNameLength is the maximum length in bytes of a file name without the
+// extension, kept well under limits of file systems (e.g. 255 bytes).
+const maxNameLength = 100
+
+// ExportOptions specifies the optional parameters to Export.
+type ExportOptions struct {
+	// Query filters posts to export. It is the Docbase query syntax.
+	Query string
+
+	// Comments appends comments of each post to the document.
+	Comments bool
+}
+
+// Export writes every post in the team to dir as a Markdown file with front
+// matter, and returns paths of the files relative to dir.
+//
+// See PostPath for the layout of the files.
+func Export(ctx context.Context, client *docbase.Client, dir string, opts ExportOptions) ([]string, error) {
+	list := client.Post.List().PerPage(100)
+	if opts.Query != "" {
+		list.Query(opts.Query)
+	}
+
+	var paths []string
+	err := list.Each(ctx, func(post docbase.Post) error {
+		rel := PostPath(&post)
+		if err := writePost(filepath.Join(dir, rel), &post, opts.Comments); err != nil {
+			return fmt.Errorf("export post %d: %w", post.ID, err)
+		}
+		paths = append(paths, rel)
+		return nil
+	})
+	return paths, err
+}
+
+func writePost(path string, post *docbase.Post, comments bool) error {
+	doc := NewDocument(post)
+	if comments && len(post.Comments) > 0 {
+		doc.Body = appendComments(doc.Body, post.Comments)
+	}
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// appendComments appends comments to the body after commentsMarker.
+func appendComments(body string, comments []docbase.Comment) string {
+	var buf strings.Builder
+	buf.WriteString(strings.TrimRight(body, "\n"))
+	buf.WriteString("\n\n" + commentsMarker + "\n")
+	for _, comment := range comments {
+		fmt.Fprintf(&buf, "\n## %s (%s)\n\n%s\n", comment.User.Username, comment.CreatedAt, strings.TrimRight(comment.Body, "\n"))
+	}
+	return buf.String()
+}
+
+// PostPath builds a path to export the post: "<group>/<id>-<slug>.md".
+// Posts shared with several groups are placed in the first group, and posts
+// without groups are placed in "_everyone" or "_private" by their scope.
+// Directories of groups whose names start with "_" get another "_" (e.g.
+// "__everyone"), not to be mixed with them.
+func PostPath(post *docbase.Post) string {
+	dir := "_" + post.Scope.String()
+	if len(post.Groups) > 0 {
+		dir = sanitize(post.Groups[0].Name)
+		if strings.HasPrefix(dir, "_") {
+			dir = "_" + dir
+		}
+	}
+	name := fmt.Sprintf("%d", post.ID)
+	if slug := Slugify(post.Title); slug != "" {
+		name = truncate(name+"-"+slug, maxNameLength)
+	}
+	return filepath.Join(dir, name+".md")
+}
+
+// Slugify makes a slug from the title for file names: letters and digits are
+// kept in lower case, and runs of other characters are replaced with "-".
+// It is cut to 100 bytes without breaking a character.
+func Slugify(title string) string {
+	var buf strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(title) {
+		if buf.Len() >= maxNameLength {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && buf.Len() > 0 {
+				buf.WriteRune('-')
+			}
+			hyphen = false
+			buf.WriteRune(r)
+			continue
+		}
+		hyphen = true
+	}
+	return truncate(buf.String(), maxNameLength)
+}
+
+// truncate cuts the name to at most max bytes without breaking a character,
+// and drops a trailing "-" left by the cut.
+func truncate(name string, max int) string {
+	if len(name) <= max {
+		return name
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(name[cut]) {
+		cut--
+	}
+	return strings.TrimRight(name[:cut], "-")
+}
+
+// sanitize replaces characters which cannot be used in a directory name.
+func sanitize(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
@@ -0,0 +1,86 @@
+package markdown_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/markdown"
+)
+
+func TestSlugify(t *testing.T) {
+	for _, test := range []struct {
+		title string
+		want  string
+	}{
+		{title: "Hello, World!", want: "hello-world"},
+		{title: "  -- Go 1.13 --  ", want: "go-1-13"},
+		{title: "週報 2019/01", want: "週報-2019-01"},
+		{title: "!!!", want: ""},
+		// Cut at 100 bytes, without a trailing hyphen.
+		{title: strings.Repeat("a", 99) + " b", want: strings.Repeat("a", 99)},
+		// 33 characters of 3 bytes are 99 bytes, and the next does not fit.
+		{title: strings.Repeat("あ", 40), want: strings.Repeat("あ", 33)},
+	} {
+		got := markdown.Slugify(test.title)
+		if got != test.want {
+			t.Errorf("Slugify(%q) = %q, want %q", test.title, got, test.want)
+		}
+		if len(got) > 100 || !utf8.ValidString(got) {
+			t.Errorf("Slugify(%q) = %q, want valid UTF-8 up to 100 bytes", test.title, got)
+		}
+	}
+}
+
+func TestPostPath(t *testing.T) {
+	for _, test := range []struct {
+		title string
+		post  docbase.Post
+		want  string
+	}{
+		{
+			title: "everyone",
+			post:  docbase.Post{ID: 1, Title: "Weekly report", Scope: docbase.ScopeEveryone},
+			want:  "_everyone/1-weekly-report.md",
+		},
+		{
+			title: "private without a slug",
+			post:  docbase.Post{ID: 2, Title: "!!!", Scope: docbase.ScopePrivate},
+			want:  "_private/2.md",
+		},
+		{
+			title: "group",
+			post:  docbase.Post{ID: 3, Title: "memo", Scope: docbase.ScopeGroup, Groups: []docbase.Group{{Name: "dev/ops"}, {Name: "qa"}}},
+			want:  "dev_ops/3-memo.md",
+		},
+		{
+			title: "group named like a scope",
+			post:  docbase.Post{ID: 4, Title: "memo", Scope: docbase.ScopeGroup, Groups: []docbase.Group{{Name: "_everyone"}}},
+			want:  "__everyone/4-memo.md",
+		},
+		{
+			title: "group sanitized like a scope",
+			post:  docbase.Post{ID: 5, Title: "memo", Scope: docbase.ScopeGroup, Groups: []docbase.Group{{Name: "/private"}}},
+			want:  "__private/5-memo.md",
+		},
+		{
+			title: "long title",
+			post:  docbase.Post{ID: 1234567, Title: strings.Repeat("あ", 40), Scope: docbase.ScopeEveryone},
+			// "1234567-" takes 8 bytes, and 30 characters take 90 bytes.
+			want: "_everyone/1234567-" + strings.Repeat("あ", 30) + ".md",
+		},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			got := markdown.PostPath(&test.post)
+			if got != filepath.FromSlash(test.want) {
+				t.Errorf("PostPath() = %q, want %q", got, test.want)
+			}
+			name := strings.TrimSuffix(filepath.Base(got), ".md")
+			if len(name) > 100 || !utf8.ValidString(name) {
+				t.Errorf("name = %q, want valid UTF-8 up to 100 bytes", name)
+			}
+		})
+	}
+}
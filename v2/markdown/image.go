@@ -0,0 +1,397 @@
+package markdown
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+// imagePattern matches image references in Markdown: ![alt](path "title").
+var imagePattern = regexp.MustCompile(`!\[([^\]]*)\]\(\s*(<[^>]*>|[^\s)]+)(\s+"[^"]*")?\s*\)`)
+
+// ImageUploader uploads local images referenced in Markdown bodies, and
+// rewrites the references to the uploaded attachments.
+type ImageUploader struct {
+	Client *docbase.Client
+
+	// Cache remembers uploaded images to skip uploading unchanged images.
+	// If it is nil, every image is uploaded each time.
+	Cache *UploadCache
+
+	// Root is a directory which images must be in. Images out of it (e.g.
+	// "/etc/passwd" or "../../.ssh/id_rsa") are rejected not to upload files
+	// which are not meant to be. If it is empty, images must be in the
+	// directory given to RewriteImages.
+	Root string
+}
+
+// localImage is an image file referenced in a body.
+type localImage struct {
+	path string
+	hash string
+}
+
+// RewriteImages uploads local images referenced in the body and returns the
+// body with the references rewritten to URLs of the attachments.
+// Relative paths are resolved from baseDir. References with URLs and ones in
+// code blocks or code spans are left as they are.
+func (u *ImageUploader) RewriteImages(ctx context.Context, body, baseDir string) (string, error) {
+	root := u.Root
+	if root == "" {
+		root = baseDir
+	}
+	lines := strings.SplitAfter(body, "\n")
+	images := map[string]*localImage{} // by the reference in the body
+	var order []string
+	err := eachTextLine(lines, func(i int) error {
+		for _, m := range imageMatches(lines[i]) {
+			ref := lines[i][m[4]:m[5]]
+			if _, ok := images[ref]; ok {
+				continue
+			}
+			path, ok := localPath(ref)
+			if !ok {
+				continue
+			}
+			path, err := resolvePath(root, baseDir, path)
+			if err != nil {
+				return err
+			}
+			hash, err := hashFile(path)
+			if err != nil {
+				return fmt.Errorf("read image: %w", err)
+			}
+			images[ref] = &localImage{path: path, hash: hash}
+			order = append(order, ref)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(images) == 0 {
+		return body, nil
+	}
+
+	urls, err := u.upload(ctx, images, order)
+	if err != nil {
+		return "", err
+	}
+	_ = eachTextLine(lines, func(i int) error {
+		line := lines[i]
+		var buf strings.Builder
+		last := 0
+		for _, m := range imageMatches(line) {
+			uploaded, ok := urls[line[m[4]:m[5]]]
+			if !ok {
+				continue
+			}
+			if strings.ContainsAny(uploaded, " ()") {
+				uploaded = "<" + uploaded + ">"
+			}
+			title := ""
+			if m[6] >= 0 {
+				title = line[m[6]:m[7]]
+			}
+			buf.WriteString(line[last:m[0]])
+			buf.WriteString("![" + line[m[2]:m[3]] + "](" + uploaded + title + ")")
+			last = m[1]
+		}
+		buf.WriteString(line[last:])
+		lines[i] = buf.String()
+		return nil
+	})
+	return strings.Join(lines, ""), nil
+}
+
+// upload uploads images which are not in the cache, and returns URLs of
+// attachments by their references.
+func (u *ImageUploader) upload(ctx context.Context, images map[string]*localImage, order []string) (map[string]string, error) {
+	urls := map[string]string{}
+	uploaded := map[string]string{} // URLs by the hash, to upload the same content once
+	var pending []string            // references of images to upload
+	for _, ref := range order {
+		image := images[ref]
+		if a, ok := u.Cache.Get(image.hash); ok {
+			urls[ref] = a.URL
+			continue
+		}
+		if _, ok := uploaded[image.hash]; ok {
+			continue
+		}
+		uploaded[image.hash] = ""
+		pending = append(pending, ref)
+	}
+
+	if len(pending) > 0 {
+		doer := u.Client.Attachment.Upload()
+		for _, ref := range pending {
+			file, err := os.Open(images[ref].path)
+			if err != nil {
+				return nil, fmt.Errorf("read image: %w", err)
+			}
+			defer file.Close()
+			doer.AddReader(filepath.Base(images[ref].path), file)
+		}
+		attachments, _, err := doer.Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("upload images: %w", err)
+		}
+		if len(attachments) != len(pending) {
+			return nil, fmt.Errorf("upload images: %d attachments are responded for %d images", len(attachments), len(pending))
+		}
+		for i, ref := range pending {
+			hash := images[ref].hash
+			uploaded[hash] = attachments[i].URL
+			u.Cache.Put(hash, attachments[i])
+		}
+	}
+
+	for _, ref := range order {
+		if _, ok := urls[ref]; !ok {
+			urls[ref] = uploaded[images[ref].hash]
+		}
+	}
+	return urls, nil
+}
+
+// hashFile calculates a SHA-256 hash of the file content without loading it
+// into memory.
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// eachTextLine calls f with indices of lines out of code blocks: fenced ones
+// and ones indented with four spaces or a tab.
+func eachTextLine(lines []string, f func(i int) error) error {
+	fence := ""
+	blank := true     // the previous line is blank
+	indented := false // in an indented code block
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if trimmed == "" {
+			blank = true
+			continue
+		}
+		// An indented code block cannot interrupt a paragraph.
+		if (blank || indented) && (strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")) {
+			indented, blank = true, false
+			continue
+		}
+		indented, blank = false, false
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		if err := f(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// imageMatches finds image references in the line out of code spans, and
+// returns their submatch indices of imagePattern.
+func imageMatches(line string) [][]int {
+	spans := codeSpans(line)
+	var matches [][]int
+	for _, m := range imagePattern.FindAllStringSubmatchIndex(line, -1) {
+		inSpan := false
+		for _, span := range spans {
+			if m[0] < span[1] && span[0] < m[1] {
+				inSpan = true
+				break
+			}
+		}
+		if !inSpan {
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// codeSpans finds ranges of code spans in the line, which are enclosed with
+// backtick strings of the same length (e.g. `code` or “co`de“).
+func codeSpans(line string) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(line); {
+		if line[i] != '`' {
+			i++
+			continue
+		}
+		start := i
+		for i < len(line) && line[i] == '`' {
+			i++
+		}
+		ticks := line[start:i]
+		end := -1
+		for j := i; j < len(line); {
+			k := strings.Index(line[j:], ticks)
+			if k < 0 {
+				break
+			}
+			k += j
+			after := k + len(ticks)
+			if after == len(line) || line[after] != '`' {
+				end = after
+				break
+			}
+			// Skip a longer backtick string.
+			for after < len(line) && line[after] == '`' {
+				after++
+			}
+			j = after
+		}
+		if end < 0 {
+			// Not closed: the backticks are literal.
+			continue
+		}
+		spans = append(spans, [2]int{start, end})
+		i = end
+	}
+	return spans
+}
+
+// localPath gets a local file path from the reference.
+// It returns false if the reference is a URL.
+func localPath(ref string) (string, bool) {
+	ref = strings.TrimSuffix(strings.TrimPrefix(ref, "<"), ">")
+	if ref == "" || strings.HasPrefix(ref, "//") || strings.HasPrefix(ref, "#") {
+		return "", false
+	}
+	if u, err := url.Parse(ref); err == nil && u.Scheme != "" {
+		return "", false
+	}
+	if unescaped, err := url.PathUnescape(ref); err == nil {
+		ref = unescaped
+	}
+	return filepath.FromSlash(ref), true
+}
+
+// resolvePath resolves the relative path from baseDir, and checks that the
+// file is in root even after following symbolic links.
+func resolvePath(root, baseDir, path string) (string, error) {
+	if filepath.IsAbs(path) || filepath.VolumeName(path) != "" {
+		return "", fmt.Errorf("image %q must be a relative path", path)
+	}
+	resolved := filepath.Join(baseDir, path)
+	outside := fmt.Errorf("image %q is out of %s", path, root)
+	if !within(root, resolved) {
+		return "", outside
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	real, err := filepath.EvalSymlinks(resolved)
+	if err != nil {
+		return "", fmt.Errorf("read image: %w", err)
+	}
+	if !within(realRoot, real) {
+		return "", outside
+	}
+	return resolved, nil
+}
+
+// within checks whether the path is in the directory.
+func within(dir, path string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// UploadCache remembers attachments uploaded for image contents by their
+// SHA-256 hashes. A nil *UploadCache remembers nothing.
+// It is safe for concurrent use.
+type UploadCache struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]docbase.Attachment
+}
+
+// LoadUploadCache loads an UploadCache from the JSON file.
+// If the file does not exist, it returns an empty cache to be saved there.
+func LoadUploadCache(path string) (*UploadCache, error) {
+	c := &UploadCache{path: path, entries: map[string]docbase.Attachment{}}
+	content, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return c, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &c.entries); err != nil {
+		return nil, fmt.Errorf("invalid upload cache %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Get gets an attachment uploaded for the content hash.
+func (c *UploadCache) Get(hash string) (docbase.Attachment, bool) {
+	if c == nil {
+		return docbase.Attachment{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	a, ok := c.entries[hash]
+	return a, ok
+}
+
+// Put remembers an attachment uploaded for the content hash.
+func (c *UploadCache) Put(hash string, attachment docbase.Attachment) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[hash] = attachment
+}
+
+// Save writes the cache to the file which it is loaded from.
+func (c *UploadCache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	content, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, content, 0644)
+}
@@ -0,0 +1,153 @@
+package markdown_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+	"github.com/kyoh86/go-docbase/v2/markdown"
+)
+
+func TestRewriteImages(t *testing.T) {
+	for _, test := range []struct {
+		title   string
+		body    string
+		want    string // "URL" is replaced with the URL of the uploaded a.png
+		uploads int
+	}{
+		{
+			title:   "relative",
+			body:    "![a](a.png)\n",
+			want:    "![a](URL)\n",
+			uploads: 1,
+		},
+		{
+			title:   "title and duplicates",
+			body:    "![a](./a.png \"A\") ![b](a.png)\n![c](img/copy.png)\n",
+			want:    "![a](URL \"A\") ![b](URL)\n![c](URL)\n",
+			uploads: 1,
+		},
+		{
+			title: "url",
+			body:  "![a](https://example.com/a.png) ![b](//example.com/b.png)\n",
+			want:  "![a](https://example.com/a.png) ![b](//example.com/b.png)\n",
+		},
+		{
+			title: "code span",
+			body:  "`![a](a.png)` and ``![b](a`.png)``\n",
+			want:  "`![a](a.png)` and ``![b](a`.png)``\n",
+		},
+		{
+			title:   "unclosed backticks",
+			body:    "` ![a](a.png)\n",
+			want:    "` ![a](URL)\n",
+			uploads: 1,
+		},
+		{
+			title: "fenced code block",
+			body:  "```\n![a](a.png)\n```\n",
+			want:  "```\n![a](a.png)\n```\n",
+		},
+		{
+			title: "indented code block",
+			body:  "text\n\n    ![a](a.png)\n\n\t![b](a.png)\n",
+			want:  "text\n\n    ![a](a.png)\n\n\t![b](a.png)\n",
+		},
+		{
+			title:   "indented paragraph continuation",
+			body:    "text\n    ![a](a.png)\n",
+			want:    "text\n    ![a](URL)\n",
+			uploads: 1,
+		},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			server := docbasetest.NewServer()
+			defer server.Close()
+			dir, cleanup := tempDir(t)
+			defer cleanup()
+			writeFile(t, filepath.Join(dir, "a.png"), "image")
+			writeFile(t, filepath.Join(dir, "img", "copy.png"), "image")
+
+			uploader := &markdown.ImageUploader{Client: server.Client()}
+			got, err := uploader.RewriteImages(context.Background(), test.body, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			urls := uploadedURLs(server, got)
+			if len(urls) != test.uploads {
+				t.Fatalf("expect %d uploads, but got %q", test.uploads, urls)
+			}
+			want := test.want
+			if len(urls) > 0 {
+				want = strings.Replace(want, "URL", urls[0], -1)
+			}
+			if got != want {
+				t.Errorf("expect %q, but got %q", want, got)
+			}
+		})
+	}
+}
+
+// uploadedURLs finds distinct URLs of attachments on the server in the body.
+func uploadedURLs(server *docbasetest.Server, body string) []string {
+	var urls []string
+	seen := map[string]bool{}
+	for _, u := range regexp.MustCompile(regexp.QuoteMeta(server.URL)+`/uploads/[^ )]+`).FindAllString(body, -1) {
+		if !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+func TestRewriteImagesOutOfRoot(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+	outside, cleanupOutside := tempDir(t)
+	defer cleanupOutside()
+	writeFile(t, filepath.Join(root, "shared", "a.png"), "image")
+	writeFile(t, filepath.Join(root, "docs", "b.png"), "image")
+	writeFile(t, filepath.Join(outside, "secret.png"), "secret")
+	if err := os.Symlink(filepath.Join(outside, "secret.png"), filepath.Join(root, "docs", "link.png")); err != nil {
+		t.Skipf("symbolic links are not available: %s", err)
+	}
+
+	for _, test := range []struct {
+		title   string
+		root    string
+		ref     string
+		wantErr bool
+	}{
+		{title: "in the base directory", ref: "b.png"},
+		{title: "in the root", root: root, ref: "../shared/a.png"},
+		{title: "out of the base directory", ref: "../shared/a.png", wantErr: true},
+		{title: "out of the root", root: root, ref: "../../" + filepath.Base(outside) + "/secret.png", wantErr: true},
+		{title: "absolute", root: root, ref: filepath.ToSlash(filepath.Join(outside, "secret.png")), wantErr: true},
+		{title: "symbolic link", root: root, ref: "link.png", wantErr: true},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			server := docbasetest.NewServer()
+			defer server.Close()
+			uploader := &markdown.ImageUploader{Client: server.Client(), Root: test.root}
+			body := "![x](" + test.ref + ")\n"
+			got, err := uploader.RewriteImages(context.Background(), body, filepath.Join(root, "docs"))
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("expect an error, but got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(uploadedURLs(server, got)) != 1 {
+				t.Errorf("expect the image to be uploaded, but got %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package markdown
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+// ImportOptions specifies the optional parameters to Import.
+type ImportOptions struct {
+	// DryRun reports what would be done, without calling APIs to change posts
+	// or writing IDs back to files.
+	DryRun bool
+
+	// Images uploads local images referenced in bodies and rewrites the
+	// references before posts are sent. Files are kept as they are.
+	// If it is nil, bodies are sent as they are.
+	Images *ImageUploader
+}
+
+// ImportAction is an action taken for a file by Import.
+type ImportAction string
+
+// Concrete actions taken for files.
+const (
+	ImportActionCreated = ImportAction("created")
+	ImportActionUpdated = ImportAction("updated")
+	ImportActionFailed  = ImportAction("failed")
+)
+
+func (a ImportAction) String() string { return string(a) }
+
+// ImportResult is a result of importing a file.
+type ImportResult struct {
+	// Path is a path of the file relative to the imported directory.
+	Path   string
+	Action ImportAction
+	PostID docbase.PostID
+	Err    error
+}
+
+// Import reads Markdown files with front matter in dir recursively, and
+// creates or updates posts from them.
+//
+// A file whose front matter has "id" updates the post with the ID. Others
+// create new posts and get the IDs written back into their front matter, so
+// that they update the posts next time. Groups in front matter are specified
+// by their names.
+//
+// Import continues even if some files fail; check Err of each result. It
+// returns an error only if it cannot go through the files.
+func Import(ctx context.Context, client *docbase.Client, dir string, opts ImportOptions) ([]ImportResult, error) {
+	var paths []string
+	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.EqualFold(filepath.Ext(path), ".md") {
+			paths = append(paths, path)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	imp := &importer{client: client, opts: opts}
+	results := make([]ImportResult, 0, len(paths))
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		result := imp.importFile(ctx, path)
+		if rel, err := filepath.Rel(dir, path); err == nil {
+			result.Path = rel
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// ImportFile creates or updates a post from a Markdown file with front matter.
+// See Import for details.
+func ImportFile(ctx context.Context, client *docbase.Client, path string, opts ImportOptions) ImportResult {
+	imp := &importer{client: client, opts: opts}
+	return imp.importFile(ctx, path)
+}
+
+type importer struct {
+	client *docbase.Client
+	opts   ImportOptions
+	groups map[string]docbase.GroupID // group IDs by name
+}
+
+func (imp *importer) importFile(ctx context.Context, path string) ImportResult {
+	result := ImportResult{Path: path, Action: ImportActionFailed}
+	doc, err := readDocumentFile(path)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	fm := doc.FrontMatter
+	result.PostID = fm.ID
+	if fm.Title == "" {
+		result.Err = errors.New("title is required in front matter")
+		return result
+	}
+	body := StripComments(doc.Body)
+
+	groupIDs, err := imp.groupIDs(ctx, fm.Groups)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	scope := fm.Scope
+	if scope == "" && len(groupIDs) > 0 {
+		scope = docbase.ScopeGroup
+	}
+	if imp.opts.Images != nil && !imp.opts.DryRun {
+		body, err = imp.opts.Images.RewriteImages(ctx, body, filepath.Dir(path))
+		if err != nil {
+			result.Err = err
+			return result
+		}
+	}
+
+	if fm.ID != 0 {
+		result.Action = ImportActionUpdated
+		if imp.opts.DryRun {
+			return result
+		}
+		edit := imp.client.Post.Edit(fm.ID).Title(fm.Title).Body(body)
+		if fm.Draft != nil {
+			edit.Draft(*fm.Draft)
+		}
+		if fm.Tags != nil {
+			edit.Tags(fm.tags())
+		}
+		if len(groupIDs) > 0 {
+			edit.Groups(groupIDs)
+		}
+		if scope != "" {
+			edit.Scope(scope)
+		}
+		if fm.Notice != nil {
+			edit.Notice(*fm.Notice)
+		}
+		if _, _, err := edit.Do(ctx); err != nil {
+			result.Action, result.Err = ImportActionFailed, err
+		}
+		return result
+	}
+
+	result.Action = ImportActionCreated
+	if imp.opts.DryRun {
+		return result
+	}
+	create := imp.client.Post.Create(fm.Title, body)
+	if fm.Draft != nil {
+		create.Draft(*fm.Draft)
+	}
+	if fm.Tags != nil {
+		create.Tags(fm.tags())
+	}
+	if len(groupIDs) > 0 {
+		create.Groups(groupIDs)
+	}
+	if scope != "" {
+		create.Scope(scope)
+	}
+	if fm.Notice != nil {
+		create.Notice(*fm.Notice)
+	}
+	if fm.AuthorID != 0 {
+		create.AuthorID(fm.AuthorID)
+	}
+	if fm.PublishedAt != nil {
+		create.PublishedAt(*fm.PublishedAt)
+	}
+	post, _, err := create.Do(ctx)
+	if err != nil {
+		result.Action, result.Err = ImportActionFailed, err
+		return result
+	}
+	result.PostID = post.ID
+	if err := WriteBackID(path, post.ID); err != nil {
+		result.Err = fmt.Errorf("post %d is created, but its ID cannot be written back: %w", post.ID, err)
+	}
+	return result
+}
+
+// tags gets tags in front matter, which is not nil to clear tags of the post
+// with "tags: []".
+func (fm FrontMatter) tags() []string {
+	if fm.Tags == nil || *fm.Tags == nil {
+		return []string{}
+	}
+	return *fm.Tags
+}
+
+// groupIDs finds IDs of groups by their names.
+func (imp *importer) groupIDs(ctx context.Context, names []string) ([]docbase.GroupID, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	if imp.groups == nil {
+		groups, err := imp.client.Group.List().All(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list groups: %w", err)
+		}
+		imp.groups = map[string]docbase.GroupID{}
+		for _, group := range groups {
+			imp.groups[group.Name] = group.ID
+		}
+	}
+	ids := make([]docbase.GroupID, 0, len(names))
+	for _, name := range names {
+		id, ok := imp.groups[name]
+		if !ok {
+			return nil, fmt.Errorf("group %q is not found", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func readDocumentFile(path string) (*Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	doc, err := ReadDocument(file)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// StripComments removes comments appended by Export from the body.
+func StripComments(body string) string {
+	i := strings.Index(body, "\n"+commentsMarker+"\n")
+	if i < 0 {
+		return body
+	}
+	return strings.TrimRight(body[:i], "\n")
+}
+
+// WriteBackID writes the post ID into front matter of the file, keeping the
+// rest of the file as it is.
+func WriteBackID(path string, id docbase.PostID) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, setID(content, id), info.Mode())
+}
+
+// setID sets the ID in front matter of the content. If the content has no
+// front matter, it will be added.
+func setID(content []byte, id docbase.PostID) []byte {
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	if len(lines) == 0 || string(bytes.TrimRight(lines[0], "\r\n")) != delimiter {
+		return append([]byte(fmt.Sprintf("%s\nid: %d\n%s\n\n", delimiter, id, delimiter)), content...)
+	}
+	newline := lines[0][len(delimiter):]
+	line := []byte(fmt.Sprintf("id: %d%s", id, newline))
+	for i := 1; i < len(lines); i++ {
+		trimmed := string(bytes.TrimRight(lines[i], "\r\n"))
+		if trimmed == delimiter {
+			break
+		}
+		if strings.HasPrefix(trimmed, "id:") {
+			// Replace an empty ID.
+			lines[i] = line
+			return bytes.Join(lines, nil)
+		}
+	}
+	lines = append(lines[:1], append([][]byte{line}, lines[1:]...)...)
+	return bytes.Join(lines, nil)
+}
@@ -0,0 +1,223 @@
+package markdown_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+	"github.com/kyoh86/go-docbase/v2/markdown"
+)
+
+// tempDir creates a temporary directory and returns it with a function to
+// remove it.
+func tempDir(t *testing.T) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "docbase-markdown-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportEdit(t *testing.T) {
+	for _, test := range []struct {
+		title       string
+		frontMatter string
+		wantDraft   bool
+		wantTags    string
+	}{
+		{title: "unspecified", frontMatter: "", wantDraft: true, wantTags: "go,ops"},
+		{title: "published", frontMatter: "draft: false\n", wantDraft: false, wantTags: "go,ops"},
+		{title: "tags replaced", frontMatter: "tags: [rust]\n", wantDraft: true, wantTags: "rust"},
+		{title: "tags cleared", frontMatter: "tags: []\n", wantDraft: true, wantTags: ""},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			server := docbasetest.NewServer()
+			defer server.Close()
+			post := server.AddPost(docbase.Post{
+				Title: "old",
+				Body:  "old",
+				Draft: true,
+				Tags:  []docbase.Tag{{Name: "go"}, {Name: "ops"}},
+			})
+			dir, cleanup := tempDir(t)
+			defer cleanup()
+			path := filepath.Join(dir, "post.md")
+			writeFile(t, path, fmt.Sprintf("---\nid: %d\ntitle: new\n%s---\n\nnew body\n", post.ID, test.frontMatter))
+
+			result := markdown.ImportFile(context.Background(), server.Client(), path, markdown.ImportOptions{})
+			if result.Err != nil || result.Action != markdown.ImportActionUpdated {
+				t.Fatalf("result = %s %v", result.Action, result.Err)
+			}
+			got, _ := server.Post(post.ID)
+			if got.Title != "new" || got.Body != "new body" {
+				t.Errorf("post = %q/%q, want new/\"new body\"", got.Title, got.Body)
+			}
+			if got.Draft != test.wantDraft {
+				t.Errorf("draft = %t, want %t", got.Draft, test.wantDraft)
+			}
+			var tags []string
+			for _, tag := range got.Tags {
+				tags = append(tags, tag.Name)
+			}
+			if strings.Join(tags, ",") != test.wantTags {
+				t.Errorf("tags = %q, want %q", tags, test.wantTags)
+			}
+		})
+	}
+}
+
+func TestImportCreate(t *testing.T) {
+	server := docbasetest.NewServer()
+	defer server.Close()
+	group := server.AddGroup(docbase.Group{Name: "dev"})
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	writeFile(t, filepath.Join(dir, "a.md"), "---\ntitle: A\ntags: [go]\ngroups: [dev]\n---\n\nbody A\n")
+	writeFile(t, filepath.Join(dir, "sub", "b.md"), "body B without front matter\n")
+	writeFile(t, filepath.Join(dir, "c.md"), "---\ntitle: C\ngroups: [unknown]\n---\n\nbody C\n")
+	writeFile(t, filepath.Join(dir, "ignored.txt"), "not a Markdown file\n")
+
+	results, err := markdown.Import(context.Background(), server.Client(), dir, markdown.ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+
+	a := results[0]
+	if a.Path != "a.md" || a.Action != markdown.ImportActionCreated || a.Err != nil {
+		t.Fatalf("a.md: %+v", a)
+	}
+	post, ok := server.Post(a.PostID)
+	if !ok {
+		t.Fatalf("post %d is not created", a.PostID)
+	}
+	if post.Body != "body A" || post.Scope != docbase.ScopeGroup || len(post.Groups) != 1 || post.Groups[0].ID != group.ID {
+		t.Errorf("post = %+v", post)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, "a.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("---\nid: %d\ntitle: A\n", a.PostID); !strings.HasPrefix(string(content), want) {
+		t.Errorf("the ID is not written back:\n%s", content)
+	}
+
+	// Title is required.
+	if c := results[1]; c.Path != "c.md" || c.Err == nil {
+		t.Errorf("c.md: %+v, want an error", c)
+	}
+	if b := results[2]; b.Path != filepath.Join("sub", "b.md") || b.Err == nil {
+		t.Errorf("sub/b.md: %+v, want an error", b)
+	}
+}
+
+func TestImportPublishedAt(t *testing.T) {
+	for _, test := range []struct {
+		title       string
+		publishedAt string
+		wantErr     bool
+		want        string
+	}{
+		{title: "time", publishedAt: "2019-01-31T10:00:00+09:00", want: "2019-01-31T10:00:00+09:00"},
+		{title: "date", publishedAt: "2019-01-31", want: "2019-01-31T00:00:00Z"},
+		{title: "invalid", publishedAt: "yesterday", wantErr: true},
+		{title: "without a zone", publishedAt: "2019-01-31 10:00", wantErr: true},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			server := docbasetest.NewServer()
+			defer server.Close()
+			dir, cleanup := tempDir(t)
+			defer cleanup()
+			path := filepath.Join(dir, "post.md")
+			writeFile(t, path, "---\ntitle: t\npublished_at: "+test.publishedAt+"\n---\n\nbody\n")
+
+			result := markdown.ImportFile(context.Background(), server.Client(), path, markdown.ImportOptions{})
+			if test.wantErr {
+				if result.Err == nil || !strings.Contains(result.Err.Error(), path) || !strings.Contains(result.Err.Error(), "published_at") {
+					t.Errorf("error = %v, want one naming %s and published_at", result.Err, path)
+				}
+				if result.PostID != 0 {
+					t.Errorf("post %d is created, want none", result.PostID)
+				}
+				return
+			}
+			if result.Err != nil {
+				t.Fatal(result.Err)
+			}
+			post, _ := server.Post(result.PostID)
+			if post.CreatedAt != test.want {
+				t.Errorf("created at = %q, want %q", post.CreatedAt, test.want)
+			}
+		})
+	}
+}
+
+func TestWriteBackID(t *testing.T) {
+	for _, test := range []struct {
+		title   string
+		content string
+		want    string
+	}{
+		{
+			title:   "no front matter",
+			content: "body\n",
+			want:    "---\nid: 42\n---\n\nbody\n",
+		},
+		{
+			title:   "no id",
+			content: "---\ntitle: t\n---\n\nbody\n",
+			want:    "---\nid: 42\ntitle: t\n---\n\nbody\n",
+		},
+		{
+			title:   "empty id",
+			content: "---\ntitle: t\nid:\n---\n\nbody\n",
+			want:    "---\ntitle: t\nid: 42\n---\n\nbody\n",
+		},
+		{
+			title:   "id in the body",
+			content: "---\ntitle: t\n---\n\nid: 1\n",
+			want:    "---\nid: 42\ntitle: t\n---\n\nid: 1\n",
+		},
+		{
+			title:   "CRLF",
+			content: "---\r\ntitle: t\r\n---\r\n\r\nbody\r\n",
+			want:    "---\r\nid: 42\r\ntitle: t\r\n---\r\n\r\nbody\r\n",
+		},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			dir, cleanup := tempDir(t)
+			defer cleanup()
+			path := filepath.Join(dir, "post.md")
+			writeFile(t, path, test.content)
+			if err := markdown.WriteBackID(path, 42); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
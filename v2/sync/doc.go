@@ -0,0 +1,15 @@
+/*
+Package sync reconciles group memberships in a Docbase team with a roster
+exported from an external system (e.g. an HR system).
+
+A Plan is computed from the roster and the current state of the team, and it
+can be shown before it is applied:
+
+	roster, err := sync.ReadCSV(file)
+	plan, err := sync.NewPlan(ctx, client, roster, sync.Options{CreateGroups: true})
+	fmt.Print(plan)
+	for _, result := range plan.Apply(ctx, client) {
+		...
+	}
+*/
+package sync
@@ -0,0 +1,218 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+)
+
+// Options specifies the optional parameters to NewPlan.
+type Options struct {
+	// Groups are names of groups to reconcile. If it is empty, the groups in
+	// the roster are reconciled. Members of a group to reconcile which are not
+	// in the roster are removed from it.
+	Groups []string
+
+	// CreateGroups creates groups which do not exist in the team.
+	// Otherwise they are reported as problems.
+	CreateGroups bool
+}
+
+// Action is a kind of Step.
+type Action string
+
+// Concrete actions of steps.
+const (
+	ActionCreateGroup = Action("create_group")
+	ActionAddUsers    = Action("add_users")
+	ActionRemoveUsers = Action("remove_users")
+)
+
+func (a Action) String() string { return string(a) }
+
+// Step is an API call to reconcile a group.
+type Step struct {
+	Action Action
+	Group  string
+	// GroupID is the ID of the group, or zero if it will be created.
+	GroupID docbase.GroupID
+	// Usernames and UserIDs are users to add or remove.
+	Usernames []string
+	UserIDs   []docbase.UserID
+}
+
+func (s Step) String() string {
+	switch s.Action {
+	case ActionCreateGroup:
+		return fmt.Sprintf("create group %s", s.Group)
+	case ActionAddUsers:
+		return fmt.Sprintf("add %s to %s", strings.Join(s.Usernames, ", "), s.Group)
+	case ActionRemoveUsers:
+		return fmt.Sprintf("remove %s from %s", strings.Join(s.Usernames, ", "), s.Group)
+	}
+	return fmt.Sprintf("%s %s", s.Action, s.Group)
+}
+
+// Plan is a list of steps to reconcile groups with a roster.
+type Plan struct {
+	Steps []Step
+	// Problems are what cannot be reconciled, like unknown users.
+	Problems []string
+}
+
+func (p *Plan) String() string {
+	var buf strings.Builder
+	for _, step := range p.Steps {
+		fmt.Fprintln(&buf, step)
+	}
+	for _, problem := range p.Problems {
+		fmt.Fprintf(&buf, "problem: %s\n", problem)
+	}
+	return buf.String()
+}
+
+// NewPlan reads the current groups and members in the team, and computes a
+// plan to reconcile them with the roster. It does not change anything.
+func NewPlan(ctx context.Context, client *docbase.Client, roster Roster, opts Options) (*Plan, error) {
+	users, err := client.User.List().IncludeUserGroups(true).All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	groups, err := client.Group.List().All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list groups: %w", err)
+	}
+
+	userIDs := map[string]docbase.UserID{} // by username
+	members := map[docbase.GroupID]map[string]bool{}
+	for _, user := range users {
+		userIDs[user.Username] = user.ID
+		for _, group := range user.Groups {
+			if members[group.ID] == nil {
+				members[group.ID] = map[string]bool{}
+			}
+			members[group.ID][user.Username] = true
+		}
+	}
+	groupIDs := map[string]docbase.GroupID{} // by name
+	for _, group := range groups {
+		groupIDs[group.Name] = group.ID
+	}
+
+	plan := new(Plan)
+	var unknown []string
+	for username := range roster {
+		if _, ok := userIDs[username]; !ok {
+			unknown = append(unknown, username)
+		}
+	}
+	sort.Strings(unknown)
+	for _, username := range unknown {
+		plan.Problems = append(plan.Problems, fmt.Sprintf("user %s is not found", username))
+	}
+
+	desired := roster.Groups()
+	names := opts.Groups
+	if len(names) == 0 {
+		for name := range desired {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		id, exists := groupIDs[name]
+		if !exists {
+			if !opts.CreateGroups {
+				plan.Problems = append(plan.Problems, fmt.Sprintf("group %s is not found", name))
+				continue
+			}
+			plan.Steps = append(plan.Steps, Step{Action: ActionCreateGroup, Group: name})
+		}
+
+		add := Step{Action: ActionAddUsers, Group: name, GroupID: id}
+		want := map[string]bool{}
+		for _, username := range desired[name] {
+			want[username] = true
+			userID, ok := userIDs[username]
+			if !ok || members[id][username] {
+				continue
+			}
+			add.Usernames = append(add.Usernames, username)
+			add.UserIDs = append(add.UserIDs, userID)
+		}
+		if len(add.UserIDs) > 0 {
+			plan.Steps = append(plan.Steps, add)
+		}
+
+		remove := Step{Action: ActionRemoveUsers, Group: name, GroupID: id}
+		var current []string
+		for username := range members[id] {
+			current = append(current, username)
+		}
+		sort.Strings(current)
+		for _, username := range current {
+			if want[username] {
+				continue
+			}
+			remove.Usernames = append(remove.Usernames, username)
+			remove.UserIDs = append(remove.UserIDs, userIDs[username])
+		}
+		if len(remove.UserIDs) > 0 {
+			plan.Steps = append(plan.Steps, remove)
+		}
+	}
+	return plan, nil
+}
+
+// Result is a result of applying a Step.
+type Result struct {
+	Step Step
+	Err  error
+}
+
+// Apply applies the steps in order, and returns their results.
+// It continues even if some steps fail; check Err of each result. Steps for a
+// group which fails to be created are skipped with an error.
+func (p *Plan) Apply(ctx context.Context, client *docbase.Client) []Result {
+	created := map[string]docbase.GroupID{}
+	failed := map[string]error{}
+	results := make([]Result, 0, len(p.Steps))
+	for _, step := range p.Steps {
+		if err := ctx.Err(); err != nil {
+			results = append(results, Result{Step: step, Err: err})
+			continue
+		}
+		if step.GroupID == 0 && step.Action != ActionCreateGroup {
+			if err, ok := failed[step.Group]; ok {
+				results = append(results, Result{Step: step, Err: fmt.Errorf("group %s is not created: %w", step.Group, err)})
+				continue
+			}
+			step.GroupID = created[step.Group]
+		}
+
+		var err error
+		switch step.Action {
+		case ActionCreateGroup:
+			var group *docbase.Group
+			group, _, err = client.Group.Create(step.Group).Do(ctx)
+			if err != nil {
+				failed[step.Group] = err
+			} else {
+				created[step.Group] = group.ID
+				step.GroupID = group.ID
+			}
+		case ActionAddUsers:
+			_, err = client.Group.AddUsers(step.GroupID, step.UserIDs).Do(ctx)
+		case ActionRemoveUsers:
+			_, err = client.Group.RemoveUsers(step.GroupID, step.UserIDs).Do(ctx)
+		default:
+			err = fmt.Errorf("unknown action %q", step.Action)
+		}
+		results = append(results, Result{Step: step, Err: err})
+	}
+	return results
+}
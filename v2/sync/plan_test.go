@@ -0,0 +1,112 @@
+package sync_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyoh86/go-docbase/v2/docbase"
+	"github.com/kyoh86/go-docbase/v2/docbase/docbasetest"
+	"github.com/kyoh86/go-docbase/v2/sync"
+)
+
+func TestPlan(t *testing.T) {
+	for _, test := range []struct {
+		title        string
+		roster       sync.Roster
+		opts         sync.Options
+		wantSteps    []string
+		wantProblems []string
+	}{
+		{
+			title:  "in sync",
+			roster: sync.Roster{"alice": {"dev"}, "bob": {"dev"}, "carol": {"ops"}},
+		},
+		{
+			title:     "add and remove",
+			roster:    sync.Roster{"alice": {"dev"}, "carol": {"dev", "ops"}},
+			wantSteps: []string{"add carol to dev", "remove bob from dev"},
+		},
+		{
+			title:        "unknown user",
+			roster:       sync.Roster{"alice": {"dev"}, "bob": {"dev"}, "dave": {"dev"}},
+			wantProblems: []string{"user dave is not found"},
+		},
+		{
+			title:        "unknown group",
+			roster:       sync.Roster{"alice": {"qa"}},
+			wantProblems: []string{"group qa is not found"},
+		},
+		{
+			title:     "created group",
+			roster:    sync.Roster{"alice": {"qa"}, "bob": {"qa"}},
+			opts:      sync.Options{CreateGroups: true},
+			wantSteps: []string{"create group qa", "add alice, bob to qa"},
+		},
+		{
+			title:     "selected groups",
+			roster:    sync.Roster{"alice": {"ops"}},
+			opts:      sync.Options{Groups: []string{"ops"}},
+			wantSteps: []string{"add alice to ops", "remove carol from ops"},
+		},
+		{
+			title:     "emptied group",
+			roster:    sync.Roster{"carol": {"ops"}},
+			opts:      sync.Options{Groups: []string{"dev", "ops"}},
+			wantSteps: []string{"remove alice, bob from dev"},
+		},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			server := docbasetest.NewServer()
+			defer server.Close()
+			alice := server.AddUser(docbase.User{Name: "Alice", Username: "alice"})
+			bob := server.AddUser(docbase.User{Name: "Bob", Username: "bob"})
+			carol := server.AddUser(docbase.User{Name: "Carol", Username: "carol"})
+			dev := server.AddGroup(docbase.Group{Name: "dev"})
+			ops := server.AddGroup(docbase.Group{Name: "ops"})
+			server.AddGroupUsers(dev.ID, alice.ID, bob.ID)
+			server.AddGroupUsers(ops.ID, carol.ID)
+			client := server.Client()
+			ctx := context.Background()
+
+			plan, err := sync.NewPlan(ctx, client, test.roster, test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var steps []string
+			for _, step := range plan.Steps {
+				steps = append(steps, step.String())
+			}
+			if !equalStrings(steps, test.wantSteps) {
+				t.Errorf("steps = %q, want %q", steps, test.wantSteps)
+			}
+			if !equalStrings(plan.Problems, test.wantProblems) {
+				t.Errorf("problems = %q, want %q", plan.Problems, test.wantProblems)
+			}
+
+			for _, result := range plan.Apply(ctx, client) {
+				if result.Err != nil {
+					t.Errorf("apply %s: %s", result.Step, result.Err)
+				}
+			}
+			again, err := sync.NewPlan(ctx, client, test.roster, test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(again.Steps) > 0 {
+				t.Errorf("steps after applied = %v, want none", again.Steps)
+			}
+		})
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
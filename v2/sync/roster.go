@@ -0,0 +1,96 @@
+package sync
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Roster is the desired membership: names of groups by usernames.
+type Roster map[string][]string
+
+// Groups gets usernames by names of groups in the roster.
+func (r Roster) Groups() map[string][]string {
+	groups := map[string][]string{}
+	for username, names := range r {
+		for _, name := range names {
+			groups[name] = appendUnique(groups[name], username)
+		}
+	}
+	for _, usernames := range groups {
+		sort.Strings(usernames)
+	}
+	return groups
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, e := range list {
+		if e == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// ReadCSV reads a roster from CSV. Each record has a username followed by
+// names of groups: "alice,dev,ops". A user can be in several records.
+// A header record starting with "username" is skipped, and empty fields are
+// ignored.
+func ReadCSV(r io.Reader) (Roster, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	roster := Roster{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return roster, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		username := strings.TrimSpace(record[0])
+		if line == 1 && strings.EqualFold(username, "username") {
+			continue
+		}
+		if username == "" {
+			return nil, fmt.Errorf("username is empty in record %d", line)
+		}
+		groups := roster[username]
+		if groups == nil {
+			groups = []string{}
+		}
+		for _, name := range record[1:] {
+			if name = strings.TrimSpace(name); name != "" {
+				groups = appendUnique(groups, name)
+			}
+		}
+		roster[username] = groups
+	}
+}
+
+// ReadYAML reads a roster from YAML, which maps usernames to lists of names
+// of groups:
+//
+//	alice: [dev, ops]
+//	bob:
+//	  - dev
+func ReadYAML(r io.Reader) (Roster, error) {
+	var roster Roster
+	if err := yaml.NewDecoder(r).Decode(&roster); err != nil && err != io.EOF {
+		return nil, err
+	}
+	if roster == nil {
+		roster = Roster{}
+	}
+	for username, groups := range roster {
+		if groups == nil {
+			roster[username] = []string{}
+		}
+	}
+	return roster, nil
+}